@@ -1,40 +1,275 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fiber-auth-api/internal/config"
 	"fiber-auth-api/internal/database"
+	"fiber-auth-api/internal/handlers"
+	"fiber-auth-api/internal/helper"
+	"fiber-auth-api/internal/lifecycle"
 	"fiber-auth-api/internal/logger"
+	"fiber-auth-api/internal/metrics"
 	"fiber-auth-api/internal/models"
 	"fiber-auth-api/internal/route"
+	"fiber-auth-api/internal/tracing"
 	"fmt"
 	"github.com/gofiber/fiber/v3"
-	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
 )
 
+const usage = `usage: api [flags] [command]
+
+commands:
+  serve                          start the HTTP server (default)
+  migrate up                     apply all pending migrations
+  migrate down [-steps n]        revert the last n migrations (default 1)
+  migrate status                 list migrations and whether they are applied
+  migrate create [-dir d] <name> create an empty up/down migration pair
+  config print                   show the effective configuration, secrets redacted`
+
 func main() {
 
-	fiberApp := fiber.New(fiber.Config{})
+	cfg, args, err := config.Load(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n\n%s\n", err, usage)
+		os.Exit(2)
+	}
 
-	logger.InitializeLogger(logger.SlogLogConfig{
-		Level: slog.LevelDebug,
-		JSON:  false,
+	err = logger.InitializeLogger(logger.SlogLogConfig{
+		Level:         cfg.Log.SlogLevel(),
+		PackageLevels: cfg.Log.PackageLevels(),
+		JSON:          cfg.Log.JSON,
+		Redact: logger.RedactConfig{
+			Keys:        cfg.Log.Redact.Keys,
+			Patterns:    cfg.Log.Redact.Patterns,
+			Allowlist:   cfg.Log.Redact.Allowlist,
+			AllowedKeys: cfg.Log.Redact.AllowedKeys,
+		},
+		Sinks: logSinks(cfg.Log),
 	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(2)
+	}
+
+	command := "serve"
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
+
+	switch command {
+	case "serve":
+		err = serve(cfg)
+	case "migrate":
+		err = runMigrate(cfg, args)
+	case "config":
+		err = runConfig(cfg, args)
+	default:
+		fmt.Fprintf(os.Stderr, "%s\n\n%s", usage, config.FlagUsage())
+		os.Exit(2)
+	}
+
+	if err != nil {
+		logger.Error(fmt.Sprintf("%s: %v", command, err))
+		logger.Close()
+		os.Exit(1)
+	}
+	logger.Close()
+}
+
+func serve(cfg *config.Config) error {
+
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	helper.ConfigureToken(cfg.Auth.JWTSecret, cfg.Auth.TokenTTL)
+
+	fiberApp := fiber.New(fiber.Config{
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
+		ErrorHandler: handlers.NewErrorHandler(logger.GetLogger()),
+
+		ProxyHeader:             cfg.Server.ProxyHeader,
+		EnableTrustedProxyCheck: true,
+		TrustedProxies:          cfg.Server.TrustedProxies,
+		EnableIPValidation:      true,
+	})
+
 	log := logger.GetLogger()
 
-	db := database.GetPsqlDatabase()
-	defer func() {
-		if err := db.ClosePsqlDb(); err != nil {
-			log.Error(fmt.Sprintf("Error closing database: %v", err))
+	// Only the database of the configured driver is opened; the memory
+	// driver runs without one.
+	var db *database.PsqlDatabase
+	var sqliteDb *database.SqliteDatabase
+	switch cfg.Database.Driver {
+	case database.DriverPostgres:
+		psqlDb, err := database.GetPsqlDatabase(cfg.Database.PsqlDsnConfig)
+		if err != nil {
+			return err
 		}
-	}()
+		db = psqlDb
+		if cfg.Metrics.Enabled {
+			if err := metrics.RegisterDBStats(db.GetPsqlDB(), cfg.Database.DBName); err != nil {
+				return err
+			}
+		}
+	case database.DriverSqlite:
+		var err error
+		if sqliteDb, err = database.NewSqliteDatabase(cfg.Database.SQLite); err != nil {
+			return err
+		}
+		if cfg.Metrics.Enabled {
+			if err := metrics.RegisterDBStats(sqliteDb.GetSqliteDB(), cfg.Database.SQLite.Path); err != nil {
+				return err
+			}
+		}
+	default:
+		log.Warn("Storing users in memory; they are lost on restart", "driver", cfg.Database.Driver)
+	}
 
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Options{
+		ServiceName: cfg.Tracing.ServiceName,
+		Exporter:    cfg.Tracing.Exporter,
+		Endpoint:    cfg.Tracing.Endpoint,
+		Insecure:    cfg.Tracing.Insecure,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Teardown runs in registration order: stop accepting and drain requests
+	// first, then flush what the handlers produced, then close the database.
+	appLifecycle := lifecycle.New(logger.ForPackage("lifecycle"))
+	appLifecycle.OnShutdown("http server", func(ctx context.Context) error {
+		if err := fiberApp.ShutdownWithContext(ctx); err != nil && !errors.Is(err, fiber.ErrNotRunning) {
+			return err
+		}
+		return nil
+	})
+	appLifecycle.OnShutdown("traces", shutdownTracing)
+	appLifecycle.OnShutdown("logs", func(ctx context.Context) error {
+		return logger.Flush()
+	})
 	app := models.Application{
 		FiberApp:   fiberApp,
 		SlogLogger: log,
-		PsqlDb:     db.GetPsqlDB(),
+		Config:     cfg,
+		Lifecycle:  appLifecycle,
+	}
+	if db != nil {
+		appLifecycle.OnShutdown("database", func(ctx context.Context) error {
+			return db.ClosePsqlDb()
+		})
+		app.PsqlDb = db.GetPsqlDB()
 	}
+	if sqliteDb != nil {
+		appLifecycle.OnShutdown("database", func(ctx context.Context) error {
+			return sqliteDb.CloseSqliteDb()
+		})
+		app.SqliteDb = sqliteDb.GetSqliteDB()
+	}
+
+	if err := route.SetupRoutes(app); err != nil {
+		return err
+	}
+
+	signalCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	defer signal.Stop(reload)
+	go reloadLogLevels(reload)
+
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- fiberApp.Listen(cfg.Server.Addr)
+	}()
+
+	select {
+	case err := <-serverErr:
+		shutdownErr := appLifecycle.Shutdown(context.Background())
+		if err != nil {
+			return fmt.Errorf("error starting up application: %w", err)
+		}
+		return shutdownErr
+	case <-signalCtx.Done():
+	}
+	stop()
+
+	log.Info("Shutdown signal received", "timeout", cfg.Server.ShutdownTimeout)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.DrainDelay+cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	appLifecycle.Drain(shutdownCtx, cfg.Server.DrainDelay)
+	if err := appLifecycle.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("shutdown did not complete cleanly: %w", err)
+	}
+
+	log.Info("Shutdown complete")
+	return nil
+}
 
-	route.SetupRoutes(app)
+// reloadLogLevels re-reads the configuration, the dotenv file included, on
+// every SIGHUP and applies its log levels. Other settings need a restart to
+// change.
+func reloadLogLevels(reload <-chan os.Signal) {
+	for range reload {
+		cfg, _, err := config.Load(os.Args[1:])
+		if err == nil {
+			err = cfg.Log.Validate()
+		}
+		if err != nil {
+			logger.Error("Failed to reload log levels", "error", err)
+			continue
+		}
 
-	logger.Error(fmt.Sprintf("Error starting up application: %v", fiberApp.Listen(":3000")))
+		logger.SetLevel(cfg.Log.SlogLevel())
+		logger.ReplacePackageLevels(cfg.Log.PackageLevels())
+		logger.Warn("Log levels reloaded", "level", cfg.Log.Level, "packages", cfg.Log.Packages)
+	}
+}
 
+func logSinks(logConfig config.LogConfig) []logger.SinkConfig {
+	sinks := make([]logger.SinkConfig, 0, len(logConfig.Sinks))
+	for _, sink := range logConfig.Sinks {
+		sinkConfig := logger.SinkConfig{
+			Output: sink.Output,
+			JSON:   sink.Format == "json",
+			File: logger.FileConfig{
+				MaxSize:     int64(sink.MaxSizeMB) << 20,
+				RotateEvery: sink.RotateEvery,
+				MaxBackups:  sink.MaxBackups,
+				MaxAge:      sink.MaxAge,
+				Compress:    sink.Compress,
+			},
+		}
+		if sink.Level != "" {
+			sinkConfig.Level = config.ParseLevel(sink.Level)
+		}
+		sinks = append(sinks, sinkConfig)
+	}
+	return sinks
+}
+
+func runConfig(cfg *config.Config, args []string) error {
+	if len(args) != 1 || args[0] != "print" {
+		return fmt.Errorf("unknown config subcommand\n\n%s", usage)
+	}
+
+	if err := cfg.Print(os.Stdout); err != nil {
+		return err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(os.Stdout, "\n# %v\n", err)
+	}
+	return nil
 }
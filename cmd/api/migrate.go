@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"fiber-auth-api/internal/config"
+	"fiber-auth-api/internal/database"
+	"fiber-auth-api/internal/logger"
+	"fiber-auth-api/internal/migrations"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+func runMigrate(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing migrate subcommand\n\n%s", usage)
+	}
+
+	subcommand, args := args[0], args[1:]
+	switch subcommand {
+	case "create":
+		return runMigrateCreate(cfg, args)
+	case "up", "down", "status":
+	default:
+		return fmt.Errorf("unknown migrate subcommand %q\n\n%s", subcommand, usage)
+	}
+
+	if err := cfg.Database.Validate(); err != nil {
+		return err
+	}
+
+	var migrator *migrations.Migrator
+	switch cfg.Database.Driver {
+	case database.DriverPostgres:
+		db, err := database.GetPsqlDatabase(cfg.Database.PsqlDsnConfig)
+		if err != nil {
+			return err
+		}
+		defer db.ClosePsqlDb()
+
+		if migrator, err = migrations.NewMigrator(db.GetPsqlDB(), logger.ForPackage("migrations")); err != nil {
+			return err
+		}
+	case database.DriverSqlite:
+		db, err := database.NewSqliteDatabase(cfg.Database.SQLite)
+		if err != nil {
+			return err
+		}
+		defer db.CloseSqliteDb()
+
+		if migrator, err = migrations.NewSqliteMigrator(db.GetSqliteDB(), logger.ForPackage("migrations")); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("the %s driver has no migrations", cfg.Database.Driver)
+	}
+
+	ctx := context.Background()
+
+	switch subcommand {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("applied %d migration(s)\n", applied)
+	case "down":
+		flags := flag.NewFlagSet("migrate down", flag.ContinueOnError)
+		steps := flags.Int("steps", 1, "number of migrations to revert")
+		if err := flags.Parse(args); err != nil {
+			return err
+		}
+
+		reverted, err := migrator.Down(ctx, *steps)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("reverted %d migration(s)\n", reverted)
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, "VERSION\tNAME\tAPPLIED AT")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.Applied {
+				appliedAt = status.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(writer, "%06d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+		return writer.Flush()
+	}
+
+	return nil
+}
+
+func runMigrateCreate(cfg *config.Config, args []string) error {
+	defaultDir := migrations.DefaultDir
+	if cfg.Database.Driver == database.DriverSqlite {
+		defaultDir = migrations.SqliteDir
+	}
+
+	flags := flag.NewFlagSet("migrate create", flag.ContinueOnError)
+	dir := flags.String("dir", defaultDir, "directory holding the migration files")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("migrate create takes exactly one name argument")
+	}
+
+	upPath, downPath, err := migrations.Create(*dir, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("created %s\ncreated %s\n", upPath, downPath)
+	return nil
+}
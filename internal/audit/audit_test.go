@@ -0,0 +1,87 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fiber-auth-api/internal/logger"
+	"fiber-auth-api/internal/repositories"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDisabledAuditLogger(t *testing.T) {
+	var out bytes.Buffer
+	auditLogger := NewAuditLogger(nil, slog.New(slog.NewTextHandler(&out, &slog.HandlerOptions{Level: slog.LevelDebug})), "key")
+	ctx := context.Background()
+	event := Event{Type: EventSignin, Outcome: OutcomeFailure}
+
+	auditLogger.Record(ctx, event)
+	if err := auditLogger.RecordTx(ctx, repositories.Repos{}, event); err != nil {
+		t.Errorf("RecordTx() = %v, want nil", err)
+	}
+	if got := strings.Count(out.String(), "Audit log disabled"); got != 2 {
+		t.Errorf("logged %d disabled notices, want 2:\n%s", got, out.String())
+	}
+
+	if _, _, err := auditLogger.Query(ctx, repositories.AuditEventFilter{}); !errors.Is(err, ErrDisabled) {
+		t.Errorf("Query() = %v, want ErrDisabled", err)
+	}
+	if _, err := auditLogger.Verify(ctx); !errors.Is(err, ErrDisabled) {
+		t.Errorf("Verify() = %v, want ErrDisabled", err)
+	}
+}
+
+func TestEventModel(t *testing.T) {
+	ctx := logger.ContextWithRequestFields(context.Background(), logger.NewRequestFields("req-7"))
+	event := Event{
+		Type:      EventSignup,
+		Outcome:   OutcomeSuccess,
+		ActorId:   "user-1",
+		TargetId:  "user-1",
+		IPAddress: "203.0.113.7",
+		UserAgent: "curl/8.0",
+		Metadata:  map[string]string{"method": "password"},
+	}
+
+	model := eventModel(ctx, event)
+	want := repositories.AuditEventModel{
+		EventType: EventSignup,
+		Outcome:   OutcomeSuccess,
+		ActorId:   "user-1",
+		TargetId:  "user-1",
+		IPAddress: "203.0.113.7",
+		UserAgent: "curl/8.0",
+		RequestId: "req-7",
+		Metadata:  map[string]string{"method": "password"},
+	}
+	if !reflect.DeepEqual(*model, want) {
+		t.Errorf("eventModel() = %+v, want %+v", *model, want)
+	}
+}
+
+func TestIdentifier(t *testing.T) {
+	auditLogger := NewAuditLogger(nil, slog.Default(), "first key")
+	otherKey := NewAuditLogger(nil, slog.Default(), "second key")
+
+	hash := auditLogger.Identifier("jane@example.com")
+	tests := []struct {
+		name string
+		got  string
+		same bool
+	}{
+		{"same value and key", auditLogger.Identifier("jane@example.com"), true},
+		{"different value", auditLogger.Identifier("john@example.com"), false},
+		{"different key", otherKey.Identifier("jane@example.com"), false},
+	}
+	for _, test := range tests {
+		if (test.got == hash) != test.same {
+			t.Errorf("%s: %s vs %s, want equal %v", test.name, test.got, hash, test.same)
+		}
+	}
+	if len(hash) != 64 || strings.Contains(hash, "jane") {
+		t.Errorf("Identifier() = %q, want a hex SHA-256 HMAC", hash)
+	}
+}
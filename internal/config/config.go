@@ -0,0 +1,396 @@
+package config
+
+import (
+	"errors"
+	"fiber-auth-api/internal/database"
+	"fmt"
+	"log/slog"
+	"net"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Config is the effective application configuration. Values are resolved in
+// increasing precedence: Default(), the YAML config file, environment
+// variables (including a .env file), and finally command-line flags.
+type Config struct {
+	Server     ServerConfig     `yaml:"server"`
+	Log        LogConfig        `yaml:"log"`
+	Auth       AuthConfig       `yaml:"auth"`
+	Pagination PaginationConfig `yaml:"pagination"`
+	Database   DatabaseConfig   `yaml:"database"`
+	Health     HealthConfig     `yaml:"health"`
+	Metrics    MetricsConfig    `yaml:"metrics"`
+	Tracing    TracingConfig    `yaml:"tracing"`
+	Admin      AdminConfig      `yaml:"admin"`
+	Audit      AuditConfig      `yaml:"audit"`
+}
+
+type ServerConfig struct {
+	Addr         string        `yaml:"addr" env:"SERVER_ADDR" flag:"addr" usage:"address the HTTP server listens on"`
+	ReadTimeout  time.Duration `yaml:"read_timeout" env:"SERVER_READ_TIMEOUT" usage:"maximum duration for reading a request"`
+	WriteTimeout time.Duration `yaml:"write_timeout" env:"SERVER_WRITE_TIMEOUT" usage:"maximum duration for writing a response"`
+	IdleTimeout  time.Duration `yaml:"idle_timeout" env:"SERVER_IDLE_TIMEOUT" usage:"maximum keep-alive idle time"`
+
+	// The client IP is only read from ProxyHeader when the connection comes
+	// from one of TrustedProxies (IPs or CIDR ranges).
+	ProxyHeader    string   `yaml:"proxy_header" env:"SERVER_PROXY_HEADER" usage:"header carrying the client IP, e.g. X-Forwarded-For"`
+	TrustedProxies []string `yaml:"trusted_proxies" env:"SERVER_TRUSTED_PROXIES" usage:"proxy IPs or CIDR ranges allowed to set the proxy header"`
+
+	// DrainDelay is how long readiness reports not-ready before listeners
+	// close; ShutdownTimeout bounds the whole teardown after that.
+	DrainDelay      time.Duration `yaml:"drain_delay" env:"SERVER_DRAIN_DELAY" usage:"time to report not-ready before closing listeners"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SERVER_SHUTDOWN_TIMEOUT" flag:"shutdown-timeout" usage:"deadline for draining in-flight requests on shutdown"`
+}
+
+type LogConfig struct {
+	Level  string          `yaml:"level" env:"LOG_LEVEL" flag:"log-level" usage:"minimum log level: debug, info, warn or error"`
+	JSON   bool            `yaml:"json" env:"LOG_JSON" flag:"log-json" usage:"write logs as JSON"`
+	Redact LogRedactConfig `yaml:"redact"`
+	Access AccessLogConfig `yaml:"access"`
+	// Sinks replace the default stdout destination and Packages overrides
+	// the level per package (e.g. repositories: debug); both are only
+	// configurable from the config file.
+	Sinks    []LogSinkConfig   `yaml:"sinks"`
+	Packages map[string]string `yaml:"packages"`
+}
+
+type LogSinkConfig struct {
+	// Output is stdout, stderr or a file path.
+	Output string `yaml:"output"`
+	Format string `yaml:"format"`
+	// Level defaults to log.level when empty.
+	Level       string        `yaml:"level"`
+	MaxSizeMB   int           `yaml:"max_size_mb"`
+	RotateEvery time.Duration `yaml:"rotate_every"`
+	MaxBackups  int           `yaml:"max_backups"`
+	MaxAge      time.Duration `yaml:"max_age"`
+	Compress    bool          `yaml:"compress"`
+}
+
+type AccessLogConfig struct {
+	Enabled bool   `yaml:"enabled" env:"LOG_ACCESS_ENABLED" usage:"write an access log entry per request"`
+	Format  string `yaml:"format" env:"LOG_ACCESS_FORMAT" flag:"access-log-format" usage:"access log format: json, combined or logfmt"`
+	// SampleRate only applies to 2xx responses; 4xx and 5xx are always
+	// logged.
+	SampleRate float64 `yaml:"sample_rate" env:"LOG_ACCESS_SAMPLE_RATE" usage:"fraction of 2xx responses written to the access log"`
+}
+
+// LogRedactConfig extends the built-in redaction rules of the logger.
+type LogRedactConfig struct {
+	Keys        []string `yaml:"keys" env:"LOG_REDACT_KEYS" usage:"extra attribute keys whose values are masked"`
+	Patterns    []string `yaml:"patterns" env:"LOG_REDACT_PATTERNS" usage:"extra regular expressions masked in messages and values"`
+	Allowlist   bool     `yaml:"allowlist" env:"LOG_REDACT_ALLOWLIST" usage:"mask every attribute not in the allowed keys"`
+	AllowedKeys []string `yaml:"allowed_keys" env:"LOG_REDACT_ALLOWED_KEYS" usage:"extra attribute keys logged as-is in allowlist mode"`
+}
+
+type AuthConfig struct {
+	JWTSecret string        `yaml:"jwt_secret" env:"JWT_SECRET" secret:"true" usage:"HMAC key used to sign access tokens"`
+	TokenTTL  time.Duration `yaml:"token_ttl" env:"JWT_TOKEN_TTL" usage:"lifetime of issued access tokens"`
+}
+
+type PaginationConfig struct {
+	DefaultPageSize int `yaml:"default_page_size" env:"PAGINATION_DEFAULT_PAGE_SIZE" flag:"page-size" usage:"page size used when a request does not ask for one"`
+	MaxPageSize     int `yaml:"max_page_size" env:"PAGINATION_MAX_PAGE_SIZE" usage:"largest page size a request may ask for"`
+	// CursorSecret signs pagination cursors. When empty the JWT secret is
+	// used, so rotating that secret also invalidates outstanding cursors.
+	CursorSecret string `yaml:"cursor_secret" env:"PAGINATION_CURSOR_SECRET" secret:"true" usage:"HMAC key used to sign pagination cursors"`
+}
+
+type DatabaseConfig struct {
+	// Driver picks where users are stored. SQLite suits single-instance
+	// deployments and the memory driver, which keeps users in the process,
+	// tests and local development. Both ignore the Postgres connection
+	// settings, and the audit log is disabled with them.
+	Driver                 string `yaml:"driver" env:"DB_DRIVER" flag:"db-driver" usage:"storage backend: postgres, sqlite or memory"`
+	database.PsqlDsnConfig `yaml:",inline"`
+	SQLite                 database.SqliteConfig `yaml:"sqlite"`
+	QueryTimeout           time.Duration         `yaml:"query_timeout" env:"DB_QUERY_TIMEOUT" flag:"db-query-timeout" usage:"timeout applied to each repository query"`
+}
+
+type HealthConfig struct {
+	CheckTimeout time.Duration `yaml:"check_timeout" env:"HEALTH_CHECK_TIMEOUT" usage:"timeout for each readiness dependency check"`
+}
+
+type MetricsConfig struct {
+	Enabled bool   `yaml:"enabled" env:"METRICS_ENABLED" usage:"expose Prometheus metrics"`
+	Path    string `yaml:"path" env:"METRICS_PATH" usage:"path the Prometheus metrics are served on"`
+}
+
+type TracingConfig struct {
+	ServiceName string  `yaml:"service_name" env:"OTEL_SERVICE_NAME" usage:"service.name reported on spans"`
+	Exporter    string  `yaml:"exporter" env:"TRACING_EXPORTER" flag:"tracing-exporter" usage:"where spans are sent: otlp, stdout or none"`
+	Endpoint    string  `yaml:"endpoint" env:"TRACING_OTLP_ENDPOINT" usage:"OTLP/HTTP collector host:port"`
+	Insecure    bool    `yaml:"insecure" env:"TRACING_OTLP_INSECURE" usage:"send OTLP over plain HTTP"`
+	SampleRatio float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO" usage:"fraction of new traces to sample, 0 to 1"`
+}
+
+// AdminConfig guards the /admin endpoints; they are disabled while Token is
+// empty.
+type AdminConfig struct {
+	Token string `yaml:"token" env:"ADMIN_TOKEN" secret:"true" usage:"bearer token required by the admin endpoints"`
+}
+
+type AuditConfig struct {
+	// IdentifierSecret keys the hashes that stand in for emails and
+	// usernames in audit events, which can never be erased from the chain.
+	// When empty the JWT secret is used.
+	IdentifierSecret string `yaml:"identifier_secret" env:"AUDIT_IDENTIFIER_SECRET" secret:"true" usage:"HMAC key used to hash identities in audit events"`
+}
+
+const (
+	minJWTSecretLength    = 32
+	minAdminTokenLength   = 32
+	minCursorSecretLength = 32
+	minAuditSecretLength  = 32
+
+	// maxPageSizeLimit bounds max_page_size, since every listing request may
+	// load and buffer that many rows.
+	maxPageSizeLimit = 1000
+)
+
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Addr:            ":3000",
+			ReadTimeout:     10 * time.Second,
+			WriteTimeout:    10 * time.Second,
+			IdleTimeout:     60 * time.Second,
+			ShutdownTimeout: 15 * time.Second,
+		},
+		Log: LogConfig{
+			Level: "info",
+			JSON:  false,
+			Access: AccessLogConfig{
+				Enabled:    true,
+				Format:     "json",
+				SampleRate: 1,
+			},
+		},
+		Auth: AuthConfig{
+			TokenTTL: 24 * time.Hour,
+		},
+		Pagination: PaginationConfig{
+			DefaultPageSize: 5,
+			MaxPageSize:     100,
+		},
+		Database: DatabaseConfig{
+			Driver:        database.DriverPostgres,
+			PsqlDsnConfig: database.DefaultPsqlDsnConfig(),
+			SQLite:        database.DefaultSqliteConfig(),
+			QueryTimeout:  5 * time.Second,
+		},
+		Health: HealthConfig{
+			CheckTimeout: 2 * time.Second,
+		},
+		Metrics: MetricsConfig{
+			Enabled: true,
+			Path:    "/metrics",
+		},
+		Tracing: TracingConfig{
+			ServiceName: "fiber-auth-api",
+			Exporter:    "none",
+			SampleRatio: 1,
+		},
+	}
+}
+
+func (config *Config) Validate() error {
+	errs := []error{
+		config.Server.Validate(),
+		config.Log.Validate(),
+		config.Auth.Validate(),
+		config.Pagination.Validate(),
+		config.Database.Validate(),
+		config.Health.Validate(),
+		config.Metrics.Validate(),
+		config.Tracing.Validate(),
+		config.Admin.Validate(),
+		config.Audit.Validate(),
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	return nil
+}
+
+func (server ServerConfig) Validate() error {
+	var errs []error
+	if server.Addr == "" {
+		errs = append(errs, fmt.Errorf("server address must be provided (SERVER_ADDR)"))
+	}
+	if server.ReadTimeout < 0 || server.WriteTimeout < 0 || server.IdleTimeout < 0 || server.DrainDelay < 0 {
+		errs = append(errs, fmt.Errorf("server timeouts must not be negative"))
+	}
+	if server.ShutdownTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("server shutdown timeout must be positive (SERVER_SHUTDOWN_TIMEOUT)"))
+	}
+	for _, proxy := range server.TrustedProxies {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				errs = append(errs, fmt.Errorf("trusted proxy %q is not an IP or CIDR range (SERVER_TRUSTED_PROXIES)", proxy))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (log LogConfig) Validate() error {
+	var errs []error
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(log.Level)); err != nil {
+		errs = append(errs, fmt.Errorf("log level must be one of debug, info, warn or error (LOG_LEVEL), got %q", log.Level))
+	}
+	if log.Access.Format != "json" && log.Access.Format != "combined" && log.Access.Format != "logfmt" {
+		errs = append(errs, fmt.Errorf("access log format must be one of json, combined or logfmt (LOG_ACCESS_FORMAT), got %q", log.Access.Format))
+	}
+	if log.Access.SampleRate < 0 || log.Access.SampleRate > 1 {
+		errs = append(errs, fmt.Errorf("access log sample rate must be between 0 and 1 (LOG_ACCESS_SAMPLE_RATE)"))
+	}
+	for i, sink := range log.Sinks {
+		if sink.Output == "" {
+			errs = append(errs, fmt.Errorf("log sink %d: output must be stdout, stderr or a file path", i))
+		}
+		if sink.Format != "text" && sink.Format != "json" {
+			errs = append(errs, fmt.Errorf("log sink %d: format must be text or json, got %q", i, sink.Format))
+		}
+		if sink.Level != "" {
+			var level slog.Level
+			if err := level.UnmarshalText([]byte(sink.Level)); err != nil {
+				errs = append(errs, fmt.Errorf("log sink %d: level must be one of debug, info, warn or error, got %q", i, sink.Level))
+			}
+		}
+		if sink.MaxSizeMB < 0 || sink.RotateEvery < 0 || sink.MaxBackups < 0 || sink.MaxAge < 0 {
+			errs = append(errs, fmt.Errorf("log sink %d: rotation settings must not be negative", i))
+		}
+	}
+	for name, levelName := range log.Packages {
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(levelName)); err != nil {
+			errs = append(errs, fmt.Errorf("log level for package %s must be one of debug, info, warn or error, got %q", name, levelName))
+		}
+	}
+	for _, pattern := range log.Redact.Patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			errs = append(errs, fmt.Errorf("invalid log redaction pattern %q (LOG_REDACT_PATTERNS): %w", pattern, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (log LogConfig) SlogLevel() slog.Level {
+	return ParseLevel(log.Level)
+}
+
+func (log LogConfig) PackageLevels() map[string]slog.Level {
+	levels := make(map[string]slog.Level, len(log.Packages))
+	for name, levelName := range log.Packages {
+		levels[name] = ParseLevel(levelName)
+	}
+	return levels
+}
+
+// ParseLevel parses a level name, falling back to info for invalid names
+// that Validate would have reported.
+func ParseLevel(name string) slog.Level {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(strings.TrimSpace(name))); err != nil {
+		return slog.LevelInfo
+	}
+	return level
+}
+
+func (auth AuthConfig) Validate() error {
+	var errs []error
+	if len(auth.JWTSecret) < minJWTSecretLength {
+		errs = append(errs, fmt.Errorf("jwt secret must be at least %d characters (JWT_SECRET)", minJWTSecretLength))
+	}
+	if auth.TokenTTL <= 0 {
+		errs = append(errs, fmt.Errorf("token ttl must be positive (JWT_TOKEN_TTL)"))
+	}
+	return errors.Join(errs...)
+}
+
+func (pagination PaginationConfig) Validate() error {
+	var errs []error
+	if pagination.DefaultPageSize < 1 {
+		errs = append(errs, fmt.Errorf("default page size must be at least 1 (PAGINATION_DEFAULT_PAGE_SIZE)"))
+	}
+	if pagination.MaxPageSize < pagination.DefaultPageSize {
+		errs = append(errs, fmt.Errorf("max page size must not be smaller than the default page size (PAGINATION_MAX_PAGE_SIZE)"))
+	}
+	if pagination.MaxPageSize > maxPageSizeLimit {
+		errs = append(errs, fmt.Errorf("max page size must be at most %d (PAGINATION_MAX_PAGE_SIZE)", maxPageSizeLimit))
+	}
+	if pagination.CursorSecret != "" && len(pagination.CursorSecret) < minCursorSecretLength {
+		errs = append(errs, fmt.Errorf("cursor secret must be at least %d characters (PAGINATION_CURSOR_SECRET)", minCursorSecretLength))
+	}
+	return errors.Join(errs...)
+}
+
+func (db DatabaseConfig) Validate() error {
+	var errs []error
+	switch db.Driver {
+	case database.DriverPostgres:
+		if err := db.PsqlDsnConfig.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	case database.DriverSqlite:
+		if err := db.SQLite.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	case database.DriverMemory:
+	default:
+		errs = append(errs, fmt.Errorf("database driver must be postgres, sqlite or memory (DB_DRIVER), got %q", db.Driver))
+	}
+	if db.QueryTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("query timeout must be positive (DB_QUERY_TIMEOUT)"))
+	}
+	return errors.Join(errs...)
+}
+
+func (health HealthConfig) Validate() error {
+	if health.CheckTimeout <= 0 {
+		return fmt.Errorf("health check timeout must be positive (HEALTH_CHECK_TIMEOUT)")
+	}
+	return nil
+}
+
+func (metrics MetricsConfig) Validate() error {
+	if metrics.Enabled && !strings.HasPrefix(metrics.Path, "/") {
+		return fmt.Errorf("metrics path must start with / (METRICS_PATH), got %q", metrics.Path)
+	}
+	return nil
+}
+
+func (tracing TracingConfig) Validate() error {
+	var errs []error
+	switch tracing.Exporter {
+	case "otlp", "stdout", "none":
+	default:
+		errs = append(errs, fmt.Errorf("tracing exporter must be one of otlp, stdout or none (TRACING_EXPORTER), got %q", tracing.Exporter))
+	}
+	if tracing.SampleRatio < 0 || tracing.SampleRatio > 1 {
+		errs = append(errs, fmt.Errorf("tracing sample ratio must be between 0 and 1 (TRACING_SAMPLE_RATIO)"))
+	}
+	if tracing.ServiceName == "" {
+		errs = append(errs, fmt.Errorf("tracing service name must be provided (OTEL_SERVICE_NAME)"))
+	}
+	return errors.Join(errs...)
+}
+
+func (admin AdminConfig) Validate() error {
+	if admin.Token != "" && len(admin.Token) < minAdminTokenLength {
+		return fmt.Errorf("admin token must be at least %d characters (ADMIN_TOKEN)", minAdminTokenLength)
+	}
+	return nil
+}
+
+func (audit AuditConfig) Validate() error {
+	if audit.IdentifierSecret != "" && len(audit.IdentifierSecret) < minAuditSecretLength {
+		return fmt.Errorf("audit identifier secret must be at least %d characters (AUDIT_IDENTIFIER_SECRET)", minAuditSecretLength)
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+func writeFile(t *testing.T, name string, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	configFile := writeFile(t, "app.yaml", `
+server:
+  addr: ":4000"
+log:
+  level: warn
+pagination:
+  default_page_size: 10
+  max_page_size: 50
+`)
+	envFile := writeFile(t, ".env", "LOG_LEVEL=error\nPAGINATION_MAX_PAGE_SIZE=60\nSERVER_TRUSTED_PROXIES=10.9.9.9\n")
+	t.Setenv("APP_CONFIG_FILE", configFile)
+	t.Setenv("PAGINATION_DEFAULT_PAGE_SIZE", "20")
+	t.Setenv("SERVER_TRUSTED_PROXIES", "10.0.0.1, 10.1.0.0/16,")
+
+	config, args, err := Load([]string{"--env-file", envFile, "--page-size", "30", "--log-json", "serve"})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		got  any
+		want any
+	}{
+		{"default", config.Server.ShutdownTimeout, 15 * time.Second},
+		{"config file", config.Server.Addr, ":4000"},
+		{"dotenv over config file", config.Log.Level, "error"},
+		{"dotenv over default", config.Pagination.MaxPageSize, 60},
+		{"flag over environment", config.Pagination.DefaultPageSize, 30},
+		{"boolean flag without value", config.Log.JSON, true},
+		{"environment over dotenv", strings.Join(config.Server.TrustedProxies, " "), "10.0.0.1 10.1.0.0/16"},
+		{"remaining arguments", strings.Join(args, " "), "serve"},
+	}
+	for _, test := range tests {
+		if test.got != test.want {
+			t.Errorf("%s: got %v, want %v", test.name, test.got, test.want)
+		}
+	}
+}
+
+// TestLoadRereadsDotenv loads twice, as a SIGHUP reload does, and checks
+// that the second load sees the edited file.
+func TestLoadRereadsDotenv(t *testing.T) {
+	envFile := writeFile(t, ".env", "LOG_LEVEL=error\n")
+	if config, _, err := Load([]string{"--env-file", envFile}); err != nil || config.Log.Level != "error" {
+		t.Fatalf("first Load = %v, %v", config, err)
+	}
+
+	if err := os.WriteFile(envFile, []byte("LOG_LEVEL=debug\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	config, _, err := Load([]string{"--env-file", envFile})
+	if err != nil {
+		t.Fatalf("second Load: %v", err)
+	}
+	if config.Log.Level != "debug" {
+		t.Errorf("reloaded level = %s, want debug", config.Log.Level)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	missingEnv := filepath.Join(t.TempDir(), "missing.env")
+	tests := []struct {
+		name    string
+		env     map[string]string
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "unknown flag",
+			args:    []string{"--nope"},
+			wantErr: "flag provided but not defined",
+		},
+		{
+			name:    "bad environment duration",
+			env:     map[string]string{"DB_QUERY_TIMEOUT": "5"},
+			wantErr: "DB_QUERY_TIMEOUT: must be a duration",
+		},
+		{
+			name:    "bad flag integer",
+			args:    []string{"--page-size", "ten"},
+			wantErr: "--page-size: must be an integer",
+		},
+		{
+			name:    "unknown config file field",
+			args:    []string{"--config", writeFile(t, "app.yaml", "server:\n  port: 3000\n")},
+			wantErr: "field port not found",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for key, value := range test.env {
+				t.Setenv(key, value)
+			}
+			_, _, err := Load(append([]string{"--env-file", missingEnv}, test.args...))
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Fatalf("Load = %v, want an error containing %q", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*Config)
+		wantErr string
+	}{
+		{name: "valid", modify: func(*Config) {}},
+		{
+			name:    "short jwt secret",
+			modify:  func(c *Config) { c.Auth.JWTSecret = "short" },
+			wantErr: "JWT_SECRET",
+		},
+		{
+			name:    "max page size below default",
+			modify:  func(c *Config) { c.Pagination.MaxPageSize = 1 },
+			wantErr: "PAGINATION_MAX_PAGE_SIZE",
+		},
+		{
+			name:    "max page size above the limit",
+			modify:  func(c *Config) { c.Pagination.MaxPageSize = 5000 },
+			wantErr: "must be at most 1000",
+		},
+		{
+			name:    "unknown log level",
+			modify:  func(c *Config) { c.Log.Level = "verbose" },
+			wantErr: "LOG_LEVEL",
+		},
+		{
+			name:    "trusted proxy that is not an address",
+			modify:  func(c *Config) { c.Server.TrustedProxies = []string{"proxy.local"} },
+			wantErr: "SERVER_TRUSTED_PROXIES",
+		},
+		{
+			name:    "unknown database driver",
+			modify:  func(c *Config) { c.Database.Driver = "mysql" },
+			wantErr: "DB_DRIVER",
+		},
+		{
+			name:   "postgres settings are ignored by the memory driver",
+			modify: func(c *Config) { c.Database.Driver, c.Database.User = "memory", "" },
+		},
+		{
+			name:    "short admin token",
+			modify:  func(c *Config) { c.Admin.Token = "admin" },
+			wantErr: "ADMIN_TOKEN",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := Default()
+			config.Auth.JWTSecret = testSecret
+			config.Database.User = "api"
+			config.Database.DBName = "auth"
+			test.modify(config)
+
+			err := config.Validate()
+			if test.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Fatalf("Validate() = %v, want an error mentioning %s", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestPrintRedactsSecrets(t *testing.T) {
+	config := Default()
+	config.Auth.JWTSecret = testSecret
+	config.Database.Password = "db-password"
+	config.Database.URL = "postgres://api:db-password@db/auth"
+
+	var out bytes.Buffer
+	if err := config.Print(&out); err != nil {
+		t.Fatalf("Print: %v", err)
+	}
+	for _, secret := range []string{testSecret, "db-password"} {
+		if strings.Contains(out.String(), secret) {
+			t.Errorf("Print leaked %q", secret)
+		}
+	}
+	if !strings.Contains(out.String(), "# pagination.default_page_size: PAGINATION_DEFAULT_PAGE_SIZE, --page-size") {
+		t.Errorf("Print did not list the overrides:\n%s", out.String())
+	}
+	if config.Auth.JWTSecret != testSecret {
+		t.Error("Print modified the configuration")
+	}
+}
@@ -0,0 +1,270 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const redacted = "********"
+
+// setting is one leaf value of Config together with the names it can be
+// overridden by.
+type setting struct {
+	Path   string
+	Env    string
+	Flag   string
+	Usage  string
+	Secret bool
+	value  reflect.Value
+}
+
+// Load resolves the configuration from every source. Flags must precede the
+// command, e.g. `api --config app.yaml --log-level debug serve`; the
+// arguments left after the flags are returned.
+func Load(args []string) (*Config, []string, error) {
+	config := Default()
+	settings := config.settings()
+
+	flags := flag.NewFlagSet("api", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	configFile := flags.String("config", os.Getenv("APP_CONFIG_FILE"), "path to a YAML config file")
+	envFile := flags.String("env-file", ".env", "path to a dotenv file; missing files are ignored")
+
+	flagValues := make(map[string]string)
+	for _, s := range settings {
+		if s.Flag == "" {
+			continue
+		}
+		flags.Var(&settingFlag{
+			name:   s.Flag,
+			values: flagValues,
+			isBool: s.value.Kind() == reflect.Bool,
+		}, s.Flag, s.Usage)
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, nil, fmt.Errorf("%w\n\n%s", err, FlagUsage())
+	}
+
+	// The dotenv file is read rather than loaded into the process
+	// environment, so variables set there still win and a reload sees
+	// edits to the file.
+	dotenv, err := godotenv.Read(*envFile)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, nil, fmt.Errorf("failed to load %s: %w", *envFile, err)
+	}
+
+	if *configFile != "" {
+		if err := config.applyFile(*configFile); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var errs []error
+	for _, s := range settings {
+		if s.Env == "" {
+			continue
+		}
+		value, ok := os.LookupEnv(s.Env)
+		if !ok {
+			value, ok = dotenv[s.Env]
+		}
+		if ok {
+			if err := setValue(s.value, value); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", s.Env, err))
+			}
+		}
+	}
+	for _, s := range settings {
+		if value, ok := flagValues[s.Flag]; ok && s.Flag != "" {
+			if err := setValue(s.value, value); err != nil {
+				errs = append(errs, fmt.Errorf("--%s: %w", s.Flag, err))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return nil, nil, fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+	}
+
+	return config, flags.Args(), nil
+}
+
+// settingFlag records the raw flag value so it can be applied after the
+// config file and environment.
+type settingFlag struct {
+	name   string
+	values map[string]string
+	isBool bool
+}
+
+func (f *settingFlag) String() string { return "" }
+
+func (f *settingFlag) Set(value string) error {
+	f.values[f.name] = value
+	return nil
+}
+
+func (f *settingFlag) IsBoolFlag() bool { return f.isBool }
+
+func FlagUsage() string {
+	var builder strings.Builder
+	writer := tabwriter.NewWriter(&builder, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "flags:")
+	fmt.Fprintln(writer, "  --config\tpath to a YAML config file (APP_CONFIG_FILE)")
+	fmt.Fprintln(writer, "  --env-file\tpath to a dotenv file, default .env")
+	for _, s := range Default().settings() {
+		if s.Flag == "" {
+			continue
+		}
+		fmt.Fprintf(writer, "  --%s\t%s (%s)\n", s.Flag, s.Usage, s.Env)
+	}
+	writer.Flush()
+	return builder.String()
+}
+
+func (config *Config) applyFile(path string) error {
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	decoder := yaml.NewDecoder(strings.NewReader(string(body)))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(config); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return nil
+}
+
+func (config *Config) settings() []setting {
+	var settings []setting
+	collectSettings(reflect.ValueOf(config).Elem(), "", &settings)
+	return settings
+}
+
+func collectSettings(value reflect.Value, prefix string, settings *[]setting) {
+	valueType := value.Type()
+	for i := 0; i < valueType.NumField(); i++ {
+		field := valueType.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+
+		if field.Type.Kind() == reflect.Struct && field.Type != reflect.TypeOf(time.Duration(0)) {
+			path := prefix
+			if !field.Anonymous {
+				path = joinPath(prefix, name)
+			}
+			collectSettings(value.Field(i), path, settings)
+			continue
+		}
+
+		*settings = append(*settings, setting{
+			Path:   joinPath(prefix, name),
+			Env:    field.Tag.Get("env"),
+			Flag:   field.Tag.Get("flag"),
+			Usage:  field.Tag.Get("usage"),
+			Secret: field.Tag.Get("secret") == "true",
+			value:  value.Field(i),
+		})
+	}
+}
+
+func joinPath(prefix string, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+func setValue(value reflect.Value, raw string) error {
+	if value.Type() == reflect.TypeOf(time.Duration(0)) {
+		parsed, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("must be a duration such as 5s, got %q", raw)
+		}
+		value.SetInt(int64(parsed))
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("must be true or false, got %q", raw)
+		}
+		value.SetBool(parsed)
+	case reflect.Int, reflect.Int64:
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("must be an integer, got %q", raw)
+		}
+		value.SetInt(parsed)
+	case reflect.Float64:
+		parsed, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("must be a number, got %q", raw)
+		}
+		value.SetFloat(parsed)
+	case reflect.Slice:
+		if value.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported list type %s", value.Type())
+		}
+		parts := make([]string, 0)
+		for _, part := range strings.Split(raw, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				parts = append(parts, part)
+			}
+		}
+		value.Set(reflect.ValueOf(parts))
+	default:
+		return fmt.Errorf("unsupported setting type %s", value.Type())
+	}
+	return nil
+}
+
+// Redacted returns a copy of the configuration with every secret replaced,
+// suitable for printing or logging.
+func (config *Config) Redacted() *Config {
+	copied := *config
+	for _, s := range copied.settings() {
+		if s.Secret && s.value.Kind() == reflect.String && s.value.String() != "" {
+			s.value.SetString(redacted)
+		}
+	}
+	return &copied
+}
+
+// Print writes the redacted configuration as YAML followed by a table of the
+// environment variable and flag that override each value.
+func (config *Config) Print(writer io.Writer) error {
+	body, err := yaml.Marshal(config.Redacted())
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(body); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(writer, "\n# overrides")
+	for _, s := range config.settings() {
+		if s.Env == "" && s.Flag == "" {
+			continue
+		}
+		line := "# " + s.Path + ": " + s.Env
+		if s.Flag != "" {
+			line += ", --" + s.Flag
+		}
+		fmt.Fprintln(writer, line)
+	}
+	return nil
+}
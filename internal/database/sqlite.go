@@ -0,0 +1,83 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+type SqliteConfig struct {
+	Path        string        `yaml:"path" env:"DB_SQLITE_PATH" flag:"db-sqlite-path" usage:"SQLite database file, created if missing"`
+	BusyTimeout time.Duration `yaml:"busy_timeout" env:"DB_SQLITE_BUSY_TIMEOUT" usage:"how long to wait for a locked SQLite database"`
+}
+
+func DefaultSqliteConfig() SqliteConfig {
+	return SqliteConfig{
+		Path:        "fiber-auth-api.db",
+		BusyTimeout: 5 * time.Second,
+	}
+}
+
+func (config SqliteConfig) Validate() error {
+	if config.Path == "" {
+		return fmt.Errorf("invalid database configuration: sqlite path must be provided (DB_SQLITE_PATH)")
+	}
+	if config.BusyTimeout < 0 {
+		return fmt.Errorf("invalid database configuration: sqlite busy timeout must not be negative (DB_SQLITE_BUSY_TIMEOUT)")
+	}
+	return nil
+}
+
+// DSN renders the configuration as a modernc.org/sqlite file: URI. Every
+// connection enables foreign keys and write-ahead logging.
+func (config SqliteConfig) DSN() string {
+	query := url.Values{}
+	query.Add("_pragma", "foreign_keys(1)")
+	query.Add("_pragma", "journal_mode(WAL)")
+	query.Add("_pragma", fmt.Sprintf("busy_timeout(%d)", config.BusyTimeout.Milliseconds()))
+	return "file:" + config.Path + "?" + query.Encode()
+}
+
+type SqliteDatabase struct {
+	sqliteDb *sql.DB
+}
+
+func NewSqliteDatabase(config SqliteConfig) (*SqliteDatabase, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open("sqlite", config.DSN())
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite allows one writer at a time; a single connection queues writes
+	// in the pool instead of failing them with SQLITE_BUSY.
+	db.SetMaxOpenConns(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("could not open %s: %w", config.Path, err)
+	}
+
+	return &SqliteDatabase{sqliteDb: db}, nil
+}
+
+func (db *SqliteDatabase) GetSqliteDB() *sql.DB {
+	return db.sqliteDb
+}
+
+func (db *SqliteDatabase) CloseSqliteDb() error {
+	if db.sqliteDb != nil {
+		return db.sqliteDb.Close()
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"fiber-auth-api/internal/audit"
+	"fiber-auth-api/internal/logger"
+	"fiber-auth-api/internal/models"
+	"fiber-auth-api/internal/types"
+	"fmt"
+	"github.com/gofiber/fiber/v3"
+	"log/slog"
+	"strings"
+)
+
+type AdminHandler struct {
+	app         models.Application
+	auditLogger *audit.AuditLogger
+}
+
+func NewAdminHandler(app models.Application, auditLogger *audit.AuditLogger) *AdminHandler {
+	return &AdminHandler{app: app, auditLogger: auditLogger}
+}
+
+// LogLevelsModel is both the response of the log level endpoints and the
+// body of updates. In an update an empty Level leaves the global level
+// alone, and a package set to "" or "default" follows the global level
+// again.
+type LogLevelsModel struct {
+	Level         string            `json:"level"`
+	Packages      map[string]string `json:"packages"`
+	KnownPackages []string          `json:"known_packages,omitempty"`
+}
+
+func (adminHandler AdminHandler) GetLogLevelsHandler(c fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(currentLogLevels())
+}
+
+func (adminHandler AdminHandler) UpdateLogLevelsHandler(c fiber.Ctx) error {
+	var update LogLevelsModel
+	if err := c.Bind().JSON(&update); err != nil {
+		return types.NewAppError(types.CodeInvalidRequest, "Please provide a valid JSON request").WithCause(err)
+	}
+
+	// Parse everything before changing anything, so a bad entry does not
+	// leave a half-applied update behind.
+	var globalLevel *slog.Level
+	if update.Level != "" {
+		level, err := parseLevel(update.Level)
+		if err != nil {
+			return types.NewAppError(types.CodeValidationFailed, err.Error())
+		}
+		globalLevel = &level
+	}
+
+	packageLevels := make(map[string]*slog.Level, len(update.Packages))
+	for name, levelName := range update.Packages {
+		if levelName == "" || strings.EqualFold(levelName, "default") {
+			packageLevels[name] = nil
+			continue
+		}
+		level, err := parseLevel(levelName)
+		if err != nil {
+			return types.NewAppError(types.CodeValidationFailed, fmt.Sprintf("package %s: %v", name, err))
+		}
+		packageLevels[name] = &level
+	}
+
+	if globalLevel != nil {
+		logger.SetLevel(*globalLevel)
+	}
+	for name, level := range packageLevels {
+		if level == nil {
+			logger.ResetPackageLevel(name)
+		} else {
+			logger.SetPackageLevel(name, *level)
+		}
+	}
+
+	levels := currentLogLevels()
+	adminHandler.app.SlogLogger.WarnContext(c.UserContext(), "Log levels changed", "level", levels.Level, "packages", levels.Packages)
+
+	event := auditEvent(c, audit.EventAdminAction, audit.OutcomeSuccess)
+	event.ActorId = audit.ActorAdminToken
+	event.Metadata = map[string]string{"action": "log_levels.update", "level": levels.Level}
+	for name, level := range levels.Packages {
+		event.Metadata["package."+name] = level
+	}
+	adminHandler.auditLogger.Record(c.UserContext(), event)
+
+	return c.Status(fiber.StatusOK).JSON(levels)
+}
+
+func currentLogLevels() LogLevelsModel {
+	packages := make(map[string]string)
+	for name, level := range logger.PackageLevels() {
+		packages[name] = strings.ToLower(level.String())
+	}
+	return LogLevelsModel{
+		Level:         strings.ToLower(logger.Level().String()),
+		Packages:      packages,
+		KnownPackages: logger.KnownPackages(),
+	}
+}
+
+func parseLevel(name string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(name)); err != nil {
+		return level, fmt.Errorf("log level must be one of debug, info, warn or error, got %q", name)
+	}
+	return level, nil
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"fiber-auth-api/internal/audit"
+	"fiber-auth-api/internal/models"
+	"fiber-auth-api/internal/repositories"
+	"fiber-auth-api/internal/types"
+	"fiber-auth-api/internal/validation"
+	"github.com/gofiber/fiber/v3"
+	"time"
+)
+
+type AuditHandler struct {
+	app         models.Application
+	auditLogger *audit.AuditLogger
+}
+
+func NewAuditHandler(app models.Application, auditLogger *audit.AuditLogger) *AuditHandler {
+	return &AuditHandler{app: app, auditLogger: auditLogger}
+}
+
+// auditEvent starts an audit event carrying the caller's address and user
+// agent.
+func auditEvent(c fiber.Ctx, eventType string, outcome string) audit.Event {
+	return audit.Event{
+		Type:      eventType,
+		Outcome:   outcome,
+		IPAddress: c.IP(),
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+	}
+}
+
+type auditEventsQuery struct {
+	EventType string    `query:"event_type"`
+	Outcome   string    `query:"outcome" enum:"success failure"`
+	ActorId   string    `query:"actor_id"`
+	TargetId  string    `query:"target_id"`
+	From      time.Time `query:"from"`
+	To        time.Time `query:"to"`
+	Page      int       `query:"page" default:"1" min:"1" max:"1000000"`
+	PerPage   int       `query:"per_page" min:"1"`
+}
+
+func (auditHandler AuditHandler) ListAuditEventsHandler(c fiber.Ctx) error {
+	var query auditEventsQuery
+	problems, err := validation.NewQueryValidator().Bind(c, &query)
+	if err != nil {
+		return types.ErrInternal.WithCause(err)
+	}
+	if len(problems) > 0 {
+		return invalidQuery(problems...)
+	}
+
+	perPage, err := pageSize(query.PerPage, auditHandler.app.Config.Pagination)
+	if err != nil {
+		return err
+	}
+	filter := repositories.AuditEventFilter{
+		EventType: query.EventType,
+		Outcome:   query.Outcome,
+		ActorId:   query.ActorId,
+		TargetId:  query.TargetId,
+		From:      query.From,
+		To:        query.To,
+		Page:      query.Page,
+		PerPage:   perPage,
+	}
+
+	events, metadata, err := auditHandler.auditLogger.Query(c.UserContext(), filter)
+	if err != nil {
+		return storeError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "successful",
+		"message": "Audit events fetched successfully",
+		"data": fiber.Map{
+			"events":   events,
+			"metadata": metadata,
+		},
+	})
+}
+
+func (auditHandler AuditHandler) VerifyAuditChainHandler(c fiber.Ctx) error {
+	verification, err := auditHandler.auditLogger.Verify(c.UserContext())
+	if err != nil {
+		return storeError(err)
+	}
+
+	if !verification.Valid {
+		auditHandler.app.SlogLogger.ErrorContext(c.UserContext(), "Audit chain verification failed", "broken_at", verification.BrokenAt, "reason", verification.Reason)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(verification)
+}
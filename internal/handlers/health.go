@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"fiber-auth-api/internal/health"
+	"fiber-auth-api/internal/models"
+	"github.com/gofiber/fiber/v3"
+	"time"
+)
+
+type HealthHandler struct {
+	app       models.Application
+	readiness *health.Registry
+	startedAt time.Time
+}
+
+func NewHealthHandler(app models.Application, readiness *health.Registry) *HealthHandler {
+	return &HealthHandler{app: app, readiness: readiness, startedAt: time.Now()}
+}
+
+// LivenessHandler only reports that the process is serving requests; it must
+// not depend on anything outside the process.
+func (healthHandler HealthHandler) LivenessHandler(c fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":         health.StatusUp,
+		"uptime_seconds": int(time.Since(healthHandler.startedAt).Seconds()),
+	})
+}
+
+func (healthHandler HealthHandler) ReadinessHandler(c fiber.Ctx) error {
+	report := healthHandler.readiness.Run(c.UserContext())
+
+	if !report.Healthy() {
+		healthHandler.app.SlogLogger.WarnContext(c.UserContext(), "Readiness check failed", "components", report.Components)
+		return c.Status(fiber.StatusServiceUnavailable).JSON(report)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(report)
+}
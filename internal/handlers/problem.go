@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fiber-auth-api/internal/i18n"
+	"fiber-auth-api/internal/logger"
+	"fiber-auth-api/internal/types"
+	"fiber-auth-api/internal/validation"
+	"github.com/gofiber/fiber/v3"
+	"log/slog"
+	"net/http"
+)
+
+const (
+	MIMEApplicationProblemJSON = "application/problem+json"
+
+	problemTypePrefix = "urn:fiber-auth-api:problem:"
+)
+
+// Problem is an RFC 7807 problem details object. Extensions are written as
+// top-level members next to the standard ones.
+type Problem struct {
+	Type       string
+	Title      string
+	Status     int
+	Detail     string
+	Instance   string
+	Code       string
+	RequestID  string
+	Extensions map[string]any
+}
+
+func (problem Problem) MarshalJSON() ([]byte, error) {
+	members := make(map[string]any, len(problem.Extensions)+7)
+	for key, value := range problem.Extensions {
+		members[key] = value
+	}
+	members["type"] = problem.Type
+	members["title"] = problem.Title
+	members["status"] = problem.Status
+	members["code"] = problem.Code
+	if problem.Detail != "" {
+		members["detail"] = problem.Detail
+	}
+	if problem.Instance != "" {
+		members["instance"] = problem.Instance
+	}
+	if problem.RequestID != "" {
+		members["request_id"] = problem.RequestID
+	}
+	return json.Marshal(members)
+}
+
+// NewErrorHandler is the application's only error renderer: handlers and
+// middleware return errors, and this maps them to problem responses.
+// Errors that are neither AppErrors nor Fiber errors are reported as
+// internal errors without exposing their message. Titles, AppError messages
+// and validation errors are translated with the request's localizer; the
+// logs always get the untranslated error.
+func NewErrorHandler(log *slog.Logger) fiber.ErrorHandler {
+	return func(c fiber.Ctx, err error) error {
+		ctx := c.UserContext()
+		problem := Problem{
+			Instance:  c.Path(),
+			RequestID: logger.RequestIDFromContext(ctx),
+		}
+
+		var appError *types.AppError
+		var fiberError *fiber.Error
+		switch {
+		case errors.As(err, &appError):
+			definition := types.LookupError(appError.Code)
+			problem.Code = definition.Code
+			problem.Status = definition.Status
+			problem.Title = definition.Title
+			problem.Detail = appError.Detail
+			problem.Extensions = appError.Extensions
+		case errors.As(err, &fiberError):
+			problem.Code = types.CodeForStatus(fiberError.Code)
+			problem.Status = fiberError.Code
+			problem.Title = http.StatusText(fiberError.Code)
+			problem.Detail = fiberError.Message
+		default:
+			definition := types.LookupError(types.CodeInternal)
+			problem.Code = definition.Code
+			problem.Status = definition.Status
+			problem.Title = definition.Title
+			problem.Detail = types.ErrInternal.Detail
+		}
+		problem.Type = problemTypePrefix + problem.Code
+
+		if localizer := i18n.FromContext(ctx); localizer != nil {
+			localizeProblem(localizer, &problem, appError)
+		}
+
+		if problem.Status >= fiber.StatusInternalServerError {
+			log.ErrorContext(ctx, "Request failed", "code", problem.Code, "error", err)
+		} else {
+			log.DebugContext(ctx, "Request rejected", "code", problem.Code, "error", err)
+		}
+
+		return c.Status(problem.Status).JSON(problem, MIMEApplicationProblemJSON)
+	}
+}
+
+func localizeProblem(localizer *i18n.Localizer, problem *Problem, appError *types.AppError) {
+	if title := localizer.T("error." + problem.Code); title != "error."+problem.Code {
+		problem.Title = title
+	}
+
+	if appError == nil {
+		if problem.Code == types.CodeInternal {
+			problem.Detail = localizer.T("detail." + types.CodeInternal)
+		}
+		return
+	}
+	if message := appError.Message; message != nil {
+		if message.Plural {
+			problem.Detail = localizer.C(message.Key, message.Count, message.Params...)
+		} else {
+			problem.Detail = localizer.T(message.Key, message.Params...)
+		}
+	}
+
+	// The extensions map and its slices may be shared with other requests
+	// through sentinel errors, so localized copies replace them.
+	switch fieldErrors := problem.Extensions["errors"].(type) {
+	case []validation.ValidationErrorField:
+		localized := make([]validation.ValidationErrorField, len(fieldErrors))
+		for i, fieldError := range fieldErrors {
+			if fieldError.Code != "" {
+				// Cross-field rules pass the other field's name as a
+				// parameter, so parameters naming a field are translated too.
+				params := []string{fieldLabel(localizer, fieldError.Field)}
+				for _, param := range fieldError.Params {
+					params = append(params, fieldLabel(localizer, param))
+				}
+				fieldError.Message = localizer.T("validation."+fieldError.Code, params...)
+			}
+			localized[i] = fieldError
+		}
+		problem.Extensions = withExtension(problem.Extensions, "errors", localized)
+	case []validation.QueryValidationError:
+		localized := make([]validation.QueryValidationError, len(fieldErrors))
+		for i, queryError := range fieldErrors {
+			if queryError.Code != "" {
+				queryError.Message = localizer.T("query."+queryError.Code, queryError.Params...)
+			}
+			localized[i] = queryError
+		}
+		problem.Extensions = withExtension(problem.Extensions, "errors", localized)
+	}
+}
+
+// fieldLabel is the localized name of a request field, or name itself when
+// the catalog has none.
+func fieldLabel(localizer *i18n.Localizer, name string) string {
+	if label := localizer.T("field." + name); label != "field."+name {
+		return label
+	}
+	return name
+}
+
+func withExtension(extensions map[string]any, key string, value any) map[string]any {
+	clone := make(map[string]any, len(extensions))
+	for k, v := range extensions {
+		clone[k] = v
+	}
+	clone[key] = value
+	return clone
+}
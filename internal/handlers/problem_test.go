@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fiber-auth-api/internal/middleware"
+	"fiber-auth-api/internal/types"
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func TestErrorHandler(t *testing.T) {
+	app := fiber.New(fiber.Config{
+		ErrorHandler: NewErrorHandler(slog.New(slog.NewTextHandler(io.Discard, nil))),
+	})
+	app.Use(middleware.RequestID())
+	app.Get("/taken", func(c fiber.Ctx) error {
+		return types.ErrEmailTaken.WithCause(errors.New("pq: duplicate key value jane@example.com"))
+	})
+	app.Get("/too-large", func(c fiber.Ctx) error {
+		return fiber.NewError(fiber.StatusRequestEntityTooLarge, "body too large")
+	})
+	app.Get("/unexpected", func(c fiber.Ctx) error {
+		return errors.New("dial tcp 10.0.0.5:5432: connection refused")
+	})
+
+	tests := []struct {
+		path    string
+		want    map[string]any
+		notWant []string
+	}{
+		{
+			path: "/taken",
+			want: map[string]any{
+				"type":     "urn:fiber-auth-api:problem:user.duplicate",
+				"title":    "User already exists",
+				"status":   float64(409),
+				"code":     "user.duplicate",
+				"detail":   "email already taken",
+				"instance": "/taken",
+				"field":    "email",
+			},
+			notWant: []string{"jane@example.com", "pq:"},
+		},
+		{
+			path: "/too-large",
+			want: map[string]any{
+				"type":   "urn:fiber-auth-api:problem:request.invalid",
+				"title":  "Request Entity Too Large",
+				"status": float64(413),
+				"code":   "request.invalid",
+				"detail": "body too large",
+			},
+		},
+		{
+			path: "/unexpected",
+			want: map[string]any{
+				"code":   "internal.error",
+				"status": float64(500),
+				"detail": types.ErrInternal.Detail,
+			},
+			notWant: []string{"10.0.0.5", "connection refused"},
+		},
+		{
+			path: "/missing",
+			want: map[string]any{
+				"code":   "request.route_not_found",
+				"status": float64(404),
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, test.path, nil))
+			if err != nil {
+				t.Fatalf("GET %s: %v", test.path, err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+
+			if contentType := resp.Header.Get(fiber.HeaderContentType); !strings.HasPrefix(contentType, MIMEApplicationProblemJSON) {
+				t.Errorf("Content-Type = %q", contentType)
+			}
+			if status := test.want["status"].(float64); resp.StatusCode != int(status) {
+				t.Errorf("status = %d, want %v", resp.StatusCode, status)
+			}
+
+			var problem map[string]any
+			if err := json.Unmarshal(body, &problem); err != nil {
+				t.Fatalf("Unmarshal(%s): %v", body, err)
+			}
+			for key, want := range test.want {
+				if problem[key] != want {
+					t.Errorf("%s = %v, want %v", key, problem[key], want)
+				}
+			}
+			if problem["request_id"] != resp.Header.Get(middleware.HeaderRequestID) {
+				t.Errorf("request_id = %v, want the X-Request-ID header", problem["request_id"])
+			}
+			for _, notWant := range test.notWant {
+				if strings.Contains(string(body), notWant) {
+					t.Errorf("response %s leaks %q", body, notWant)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"errors"
+	"fiber-auth-api/internal/config"
+	"fiber-auth-api/internal/types"
+	"fiber-auth-api/internal/validation"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// bindRequest binds the JSON body into model and validates it against the
+// model's tags. A rejected request comes back as the problem to return and
+// the reason to record in metrics.
+func bindRequest(c fiber.Ctx, model any, example fiber.Map) (reason string, err error) {
+	fieldErrors, err := validation.BindAndValidate(c, model)
+	if err != nil {
+		if errors.Is(err, validation.ErrInvalidTag) {
+			return "internal", types.ErrInternal.WithCause(err)
+		}
+		if invalidFieldErr, ok := validation.IsInvalidFieldError(err); ok {
+			return "invalid_request", types.NewAppError(types.CodeUnknownFields, "invalid field names in request").
+				WithPluralMessage("detail."+types.CodeUnknownFields, len(invalidFieldErr.Fields), strings.Join(invalidFieldErr.Fields, ", ")).
+				WithExtension("invalid_fields", invalidFieldErr.Fields).
+				WithExtension("request_example", example)
+		}
+		return "invalid_request", types.NewAppError(types.CodeInvalidRequest, "Please provide a valid JSON request with the required fields").
+			WithMessage("detail."+types.CodeInvalidRequest).
+			WithCause(err).
+			WithExtension("request_example", example)
+	}
+
+	if len(fieldErrors) > 0 {
+		return "validation", types.NewAppError(types.CodeValidationFailed, "validation failed").
+			WithPluralMessage("detail."+types.CodeValidationFailed, len(fieldErrors)).
+			WithExtension("errors", fieldErrors).
+			WithExtension("request_example", example)
+	}
+	return "", nil
+}
+
+func invalidQuery(problems ...validation.QueryValidationError) error {
+	return types.NewAppError(types.CodeInvalidQuery, "invalid query parameters").
+		WithPluralMessage("detail."+types.CodeInvalidQuery, len(problems)).
+		WithExtension("errors", problems)
+}
+
+// pageSize applies the configured default to a per_page parameter that was
+// left out, and reports one above the configured maximum the way a max tag
+// would.
+func pageSize(perPage int, pagination config.PaginationConfig) (int, error) {
+	if perPage == 0 {
+		return pagination.DefaultPageSize, nil
+	}
+	if perPage > pagination.MaxPageSize {
+		limit := strconv.Itoa(pagination.MaxPageSize)
+		return 0, invalidQuery(validation.QueryValidationError{
+			Parameter: "per_page",
+			Value:     strconv.Itoa(perPage),
+			Code:      validation.QueryCodeTooLarge,
+			Message:   "value must be at most " + limit,
+			Params:    []string{limit},
+		})
+	}
+	return perPage, nil
+}
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"errors"
+	"fiber-auth-api/internal/config"
+	"fiber-auth-api/internal/types"
+	"math"
+	"testing"
+)
+
+func TestPageSize(t *testing.T) {
+	pagination := config.PaginationConfig{DefaultPageSize: 5, MaxPageSize: 100}
+	tests := []struct {
+		perPage int
+		want    int
+		wantErr bool
+	}{
+		{0, 5, false},
+		{1, 1, false},
+		{100, 100, false},
+		{101, 0, true},
+		{math.MaxInt, 0, true},
+	}
+	for _, test := range tests {
+		got, err := pageSize(test.perPage, pagination)
+		if got != test.want || (err != nil) != test.wantErr {
+			t.Errorf("pageSize(%d) = %d, %v, want %d", test.perPage, got, err, test.want)
+		}
+		if err != nil && !errors.Is(err, types.NewAppError(types.CodeInvalidQuery, "")) {
+			t.Errorf("pageSize(%d) error = %v, want an invalid query error", test.perPage, err)
+		}
+	}
+}
@@ -2,37 +2,54 @@ package handlers
 
 import (
 	"errors"
+	"fiber-auth-api/internal/audit"
 	"fiber-auth-api/internal/helper"
+	"fiber-auth-api/internal/logger"
+	"fiber-auth-api/internal/metrics"
 	"fiber-auth-api/internal/models"
+	"fiber-auth-api/internal/pagination"
 	"fiber-auth-api/internal/repositories"
 	"fiber-auth-api/internal/types"
 	"fiber-auth-api/internal/validation"
+	"fmt"
 	"time"
 
 	"github.com/gofiber/fiber/v3"
 )
 
 type UserHandler struct {
-	app     models.Application
-	dbModel *models.DbModel
+	app         models.Application
+	dbModel     *models.DbModel
+	auditLogger *audit.AuditLogger
+	cursors     *pagination.CursorCodec
 }
 
-func NewUserHandler(app models.Application, dbModel *models.DbModel) *UserHandler {
-	return &UserHandler{app: app, dbModel: dbModel}
+func NewUserHandler(app models.Application, dbModel *models.DbModel, auditLogger *audit.AuditLogger) *UserHandler {
+	cursorSecret := app.Config.Pagination.CursorSecret
+	if cursorSecret == "" {
+		cursorSecret = app.Config.Auth.JWTSecret
+	}
+	return &UserHandler{
+		app:         app,
+		dbModel:     dbModel,
+		auditLogger: auditLogger,
+		cursors:     pagination.NewCursorCodec(cursorSecret),
+	}
 }
 
 var (
-	exampleEmail = "user@example.com"
+	exampleEmail         = "user@example.com"
+	examplePassword      = "correct-horse-battery"
 	signupRequestExample = fiber.Map{
-		"email":    exampleEmail,
-		"password": exampleEmail,
-		"username": "johndoe",
+		"email":      exampleEmail,
+		"password":   examplePassword,
+		"username":   "johndoe",
 		"first_name": "John",
-		"last_name": "Doe",
+		"last_name":  "Doe",
 	}
 	signinRequestExample = fiber.Map{
 		"email":    exampleEmail,
-		"password": exampleEmail,
+		"password": examplePassword,
 	}
 )
 
@@ -41,61 +58,57 @@ type queryParams struct {
 }
 
 func (userHandler UserHandler) SignUpHandler(c fiber.Ctx) error {
-	
-	user := new(repositories.UserSignupModel)
-	if err := validation.InvalidFieldValidation(c, map[string]bool{
-		"email":    true,
-		"password": true,
-		"username": true,
-		"first_name": true,
-		"last_name": true,
-	}, user); err != nil {
-		if invalidFieldErr, ok := validation.IsInvalidFieldError(err); ok {
-			userHandler.app.SlogLogger.Error("Invalid field error", "error", invalidFieldErr)
-			return userHandler.BadRequestFieldResponseError(c, signupRequestExample, fiber.Map{
-				"invalid_fields": invalidFieldErr.Fields,
-			})
-		}
 
-		userHandler.app.SlogLogger.Error("Invalid json body", "error", err)
-		return userHandler.BadRequestResponseError(c, signupRequestExample)
+	user := new(repositories.UserSignupModel)
+	if reason, err := bindRequest(c, user, signupRequestExample); err != nil {
+		metrics.RecordSignup(metrics.OutcomeFailure, reason)
+		return err
 	}
 
-	v := validation.NewErrorValidator()
-	v.Check(user.Email != "", "email", "email must be provided")
-	v.Check(user.Password != "", "password", "password must be provided")
-	v.Check(user.Username != "", "username", "username must be provided")
-	v.Check(user.FirstName != "", "first_name", "first name must be provided")
-	v.Check(user.LastName != "", "last_name", "last name must be provided")
-	
-	if !v.IsValid() {
-		return userHandler.ValidationResponseError(c, signupRequestExample, v.ValidationErrorField)
-	}
-	
-	hashedPassword, err := helper.HashPassword(user.Password)
+	hashedPassword, err := helper.HashPassword(c.UserContext(), user.Password)
 	if err != nil {
-		userHandler.app.SlogLogger.Error("Failed to hash password", "error", err)
-		return userHandler.InternalServerErrorResponseError(c)
+		metrics.RecordSignup(metrics.OutcomeFailure, "internal")
+		return types.ErrInternal.WithCause(fmt.Errorf("failed to hash password: %w", err))
 	}
 
 	userResponse := &repositories.UserCreateDbModel{
-		Email:     user.Email,
-		PasswordHash:  hashedPassword,
-		Username:  user.Username,
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
-		IsActive:  true,
+		Email:        user.Email,
+		PasswordHash: hashedPassword,
+		Username:     user.Username,
+		FirstName:    user.FirstName,
+		LastName:     user.LastName,
+		IsActive:     true,
 	}
 
-	err = userHandler.dbModel.UserDbModel.CreateUser(userResponse)
+	// The account and its signup event are stored together or not at all.
+	err = userHandler.dbModel.Repos.WithAuditedTx(c.UserContext(), func(tx repositories.Repos) error {
+		if err := tx.Users.CreateUser(c.UserContext(), userResponse); err != nil {
+			return err
+		}
+		event := auditEvent(c, audit.EventSignup, audit.OutcomeSuccess)
+		event.ActorId = userResponse.UserId
+		event.TargetId = userResponse.UserId
+		return userHandler.auditLogger.RecordTx(c.UserContext(), tx, event)
+	})
 
 	if err != nil {
 		if errors.Is(err, types.ErrDuplicateUser) {
-			return userHandler.ConflictResponseError(c, "User already exists")
+			metrics.RecordSignup(metrics.OutcomeFailure, "duplicate")
+			event := auditEvent(c, audit.EventSignup, audit.OutcomeFailure)
+			event.Metadata = map[string]string{
+				"reason":        "duplicate",
+				"email_hash":    userHandler.auditLogger.Identifier(user.Email),
+				"username_hash": userHandler.auditLogger.Identifier(user.Username),
+			}
+			userHandler.auditLogger.Record(c.UserContext(), event)
+			return err
 		}
-		return userHandler.InternalServerErrorResponseError(c)
+		metrics.RecordSignup(metrics.OutcomeFailure, "internal")
+		return storeError(err)
 	}
 
+	logger.SetUserID(c.UserContext(), userResponse.UserId)
+	metrics.RecordSignup(metrics.OutcomeSuccess, "")
 	return userHandler.SuccessResponse(c, "User created successfully", user.Email)
 
 }
@@ -103,56 +116,57 @@ func (userHandler UserHandler) SignUpHandler(c fiber.Ctx) error {
 func (userHandler UserHandler) SignInHandler(c fiber.Ctx) error {
 
 	user := new(repositories.UserSigninModel)
-
-	if err := validation.InvalidFieldValidation(c, map[string]bool{
-		"email":    true,
-		"password": true,
-	}, user); err != nil {
-		if invalidFieldErr, ok := validation.IsInvalidFieldError(err); ok {
-			return userHandler.BadRequestFieldResponseError(c, signinRequestExample, fiber.Map{
-				"invalid_fields": invalidFieldErr.Fields,
-			})
-		}
-		userHandler.app.SlogLogger.Error("Invalid json body", "error", err)
-		return userHandler.BadRequestResponseError(c, signinRequestExample)
+	if reason, err := bindRequest(c, user, signinRequestExample); err != nil {
+		metrics.RecordSignin(metrics.OutcomeFailure, reason)
+		return err
 	}
 
-	v := validation.NewErrorValidator()
-	v.Check(user.Email != "", "email", "email must be provided")
-	v.Check(user.Password != "", "password", "password must be provided")
-
-	if !v.IsValid() {
-		return userHandler.ValidationResponseError(c, signinRequestExample, v.ValidationErrorField)
-	}
-
-	userResponse, err := userHandler.dbModel.UserDbModel.AuthenticateUser(user.Email)
+	userResponse, err := userHandler.dbModel.UserDbModel.AuthenticateUser(c.UserContext(), user.Email)
 
 	if err != nil {
 		if errors.Is(err, types.ErrUserNotFound) {
-			return userHandler.NotFoundResponseError(c)
+			metrics.RecordSignin(metrics.OutcomeFailure, "user_not_found")
+			event := auditEvent(c, audit.EventSignin, audit.OutcomeFailure)
+			event.Metadata = map[string]string{"reason": "user_not_found", "email_hash": userHandler.auditLogger.Identifier(user.Email)}
+			userHandler.auditLogger.Record(c.UserContext(), event)
+			return types.ErrInvalidCredentials
 		}
-		return userHandler.InternalServerErrorResponseError(c)
+		metrics.RecordSignin(metrics.OutcomeFailure, "internal")
+		return storeError(err)
 	}
 
-	if err := helper.VerifyPassword(userResponse.PasswordHash, user.Password); err != nil {
-		// "Invalid email or password"
-		return userHandler.UnauthorizedResponseError(c)
+	if err := helper.VerifyPassword(c.UserContext(), userResponse.PasswordHash, user.Password); err != nil {
+		// Nobody was authenticated: the account only appears as the target,
+		// and the request is not logged as the user's.
+		metrics.RecordSignin(metrics.OutcomeFailure, "invalid_password")
+		event := auditEvent(c, audit.EventSignin, audit.OutcomeFailure)
+		event.TargetId = userResponse.UserId
+		event.Metadata = map[string]string{"reason": "invalid_password"}
+		userHandler.auditLogger.Record(c.UserContext(), event)
+		return types.ErrInvalidCredentials
 	}
 
+	logger.SetUserID(c.UserContext(), userResponse.UserId)
+
 	token, err := helper.CreateToken(userResponse.Email)
 
 	if err != nil {
-		userHandler.app.SlogLogger.Error("Failed to create token", "error", err)
-		return userHandler.InternalServerErrorResponseError(c)
+		metrics.RecordSignin(metrics.OutcomeFailure, "internal")
+		return types.ErrInternal.WithCause(fmt.Errorf("failed to create token: %w", err))
 	}
 
 	c.Cookie(&fiber.Cookie{
 		Name:     "jwt",
 		Value:    token,
-		Expires:  time.Now().Add(time.Hour * 24),
+		Expires:  time.Now().Add(userHandler.app.Config.Auth.TokenTTL),
 		HTTPOnly: true,
 	})
 
+	metrics.RecordSignin(metrics.OutcomeSuccess, "")
+	event := auditEvent(c, audit.EventSignin, audit.OutcomeSuccess)
+	event.ActorId = userResponse.UserId
+	event.TargetId = userResponse.UserId
+	userHandler.auditLogger.Record(c.UserContext(), event)
 	return userHandler.SuccessResponse(c, "User created successfully", token)
 
 }
@@ -161,27 +175,72 @@ func (userHandler UserHandler) SignOutHandler(c fiber.Ctx) error { return nil }
 
 func (userHandler UserHandler) ResetPasswordHandler(c fiber.Ctx) error { return nil }
 
+type usersQuery struct {
+	Page            int       `query:"page" default:"1" min:"1" max:"1000000"`
+	PerPage         int       `query:"per_page" min:"1"`
+	Sort            string    `query:"sort" default:"created_at" enum:"created_at updated_at last_login_at username email first_name last_name"`
+	Order           string    `query:"order" default:"desc" enum:"asc desc"`
+	IsActive        *bool     `query:"is_active"`
+	IsEmailVerified *bool     `query:"is_email_verified"`
+	CreatedFrom     time.Time `query:"created_from"`
+	CreatedTo       time.Time `query:"created_to"`
+	Search          string    `query:"search" min:"2" max:"100"`
+	// Cursor switches to keyset pagination; it is empty for the first page
+	// and a next_cursor or prev_cursor after that.
+	Cursor *string `query:"cursor"`
+}
+
 func (userHandler UserHandler) GetAllUsersHandler(c fiber.Ctx) error {
 
-	validator := validation.NewQueryValidator()
-    
-    // Define validation rules
-    rules := map[string]string{
-        "age":    "number",
-        "status": "string",
-        "search": "string",
-    }
-    
-    // Validate query parameters
-    if errors := validator.ValidateQuery(c, rules); len(errors) > 0 {
-        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-            "errors": errors,
-        })
-    }
-
-	users, metadata, err := userHandler.dbModel.UserDbModel.GetAllUsers()
+	var query usersQuery
+	problems, err := validation.NewQueryValidator().Bind(c, &query)
 	if err != nil {
-		return userHandler.InternalServerErrorResponseError(c)
+		return types.ErrInternal.WithCause(err)
+	}
+	if len(problems) > 0 {
+		return invalidQuery(problems...)
+	}
+
+	perPage, err := pageSize(query.PerPage, userHandler.app.Config.Pagination)
+	if err != nil {
+		return err
+	}
+	filter := repositories.UserListFilter{
+		Page:            query.Page,
+		PerPage:         perPage,
+		SortBy:          query.Sort,
+		SortDesc:        query.Order == "desc",
+		IsActive:        query.IsActive,
+		IsEmailVerified: query.IsEmailVerified,
+		CreatedFrom:     query.CreatedFrom,
+		CreatedTo:       query.CreatedTo,
+	}
+
+	if query.Cursor != nil && query.Search != "" {
+		return invalidQuery(validation.QueryValidationError{
+			Parameter: "cursor",
+			Value:     *query.Cursor,
+			Code:      validation.QueryCodeUnexpected,
+			Message:   "unexpected parameter",
+		})
+	}
+	if query.Cursor != nil {
+		return userHandler.listUsersByCursor(c, filter, *query.Cursor)
+	}
+	if query.Search != "" {
+		results, metadata, err := userHandler.dbModel.UserDbModel.SearchUsers(c.UserContext(), filter, validation.NormalizeText(query.Search))
+		if err != nil {
+			return storeError(err)
+		}
+		return userHandler.SuccessResponse(c, "Users found successfully", fiber.Map{
+			"users":    results,
+			"metadata": metadata,
+		})
+	}
+
+	users, metadata, err := userHandler.dbModel.UserDbModel.GetAllUsers(c.UserContext(), filter)
+	if err != nil {
+		return storeError(err)
 	}
 
 	return userHandler.SuccessResponse(c, "All users fetched successfully", fiber.Map{
@@ -190,22 +249,122 @@ func (userHandler UserHandler) GetAllUsersHandler(c fiber.Ctx) error {
 	})
 }
 
+// listUsersByCursor serves the keyset mode of the user listing. The order
+// of later pages comes from the cursor, so following next_cursor and
+// prev_cursor keeps the listing's order even if the order parameter is
+// dropped.
+func (userHandler UserHandler) listUsersByCursor(c fiber.Ctx, filter repositories.UserListFilter, token string) error {
+	if filter.SortBy != "created_at" {
+		return invalidQuery(validation.QueryValidationError{
+			Parameter: "sort",
+			Value:     filter.SortBy,
+			Code:      validation.QueryCodeNotAllowed,
+			Message:   "value must be one of: created_at",
+			Params:    []string{"created_at"},
+		})
+	}
+
+	var after *repositories.UserKeyset
+	var cursor pagination.Cursor
+	if token != "" {
+		var err error
+		if cursor, err = userHandler.cursors.Decode(token); err != nil {
+			return invalidQuery(validation.QueryValidationError{
+				Parameter: "cursor",
+				Value:     token,
+				Code:      validation.QueryCodeInvalidCursor,
+				Message:   "cursor is invalid or has been tampered with",
+			})
+		}
+		filter.SortDesc = cursor.Desc
+		after = &repositories.UserKeyset{CreatedAt: cursor.CreatedAt, UserId: cursor.Id}
+	}
+
+	users, hasMore, err := userHandler.dbModel.UserDbModel.GetUsersByKeyset(c.UserContext(), filter, after, cursor.Backward)
+	if err != nil {
+		return storeError(err)
+	}
+
+	// Whatever direction was read, a cursor means rows exist on the side
+	// it came from.
+	metadata := &types.CursorMetadata{
+		PerPage:             filter.PerPage,
+		HasNext:             hasMore,
+		HasPrev:             after != nil,
+		TotalCurrentRecords: len(users),
+	}
+	if cursor.Backward {
+		metadata.HasNext, metadata.HasPrev = after != nil, hasMore
+	}
+	if len(users) > 0 {
+		first, last := users[0], users[len(users)-1]
+		if metadata.HasNext {
+			metadata.NextCursor = userHandler.cursors.Encode(pagination.Cursor{CreatedAt: last.CreatedAt, Id: last.UserId, Desc: filter.SortDesc})
+		}
+		if metadata.HasPrev {
+			metadata.PrevCursor = userHandler.cursors.Encode(pagination.Cursor{CreatedAt: first.CreatedAt, Id: first.UserId, Desc: filter.SortDesc, Backward: true})
+		}
+	}
+
+	return userHandler.SuccessResponse(c, "All users fetched successfully", fiber.Map{
+		"users":    users,
+		"metadata": metadata,
+	})
+}
+
+type autocompleteQuery struct {
+	Prefix string `query:"prefix" min:"1" max:"100"`
+	Limit  int    `query:"limit" default:"10" min:"1" max:"50"`
+}
+
+func (userHandler UserHandler) AutocompleteUsersHandler(c fiber.Ctx) error {
+	var query autocompleteQuery
+	problems, err := validation.NewQueryValidator().Bind(c, &query)
+	if err != nil {
+		return types.ErrInternal.WithCause(err)
+	}
+	if len(problems) > 0 {
+		return invalidQuery(problems...)
+	}
+
+	prefix := validation.NormalizeText(query.Prefix)
+	if prefix == "" {
+		return userHandler.SuccessResponse(c, "User suggestions fetched successfully", []*repositories.UserSuggestionModel{})
+	}
+	suggestions, err := userHandler.dbModel.UserDbModel.AutocompleteUsers(c.UserContext(), prefix, query.Limit)
+	if err != nil {
+		return storeError(err)
+	}
+	return userHandler.SuccessResponse(c, "User suggestions fetched successfully", suggestions)
+}
+
 func (userHandler UserHandler) GetUserByIdHandler(c fiber.Ctx) error {
 
 	userId := c.Params("id")
 
-	user, err := userHandler.dbModel.UserDbModel.FindUserById(userId)
+	user, err := userHandler.dbModel.UserDbModel.FindUserById(c.UserContext(), userId)
 	if err != nil {
-		return userHandler.NotFoundResponseError(c)
+		return storeError(err)
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"status":  "successful",
-        "message": "All user fetched successfully",
-        "data":    user,
+		"message": "All user fetched successfully",
+		"data":    user,
 	})
 }
 
 func (userHandler UserHandler) GetUserByUsernameHandler(c fiber.Ctx) error { return nil }
 
 func (userHandler UserHandler) GetUserByEmailHandler(c fiber.Ctx) error { return nil }
+
+// storeError reports a failed UserStore call: the store's own API errors,
+// such as a missing user or an unavailable database, as they are, anything
+// else as an internal error.
+func storeError(err error) error {
+	var appError *types.AppError
+	if errors.As(err, &appError) {
+		return err
+	}
+	return types.ErrInternal.WithCause(err)
+}
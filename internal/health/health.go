@@ -0,0 +1,101 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+type CheckFunc func(ctx context.Context) error
+
+type ComponentStatus struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+type Report struct {
+	Status     string            `json:"status"`
+	Components []ComponentStatus `json:"components"`
+}
+
+func (report Report) Healthy() bool {
+	return report.Status == StatusUp
+}
+
+type check struct {
+	name string
+	fn   CheckFunc
+}
+
+// Registry runs a set of named dependency checks concurrently, each bounded
+// by the registry timeout.
+type Registry struct {
+	timeout time.Duration
+	checks  []check
+}
+
+func NewRegistry(timeout time.Duration) *Registry {
+	return &Registry{timeout: timeout}
+}
+
+func (registry *Registry) Register(name string, fn CheckFunc) {
+	registry.checks = append(registry.checks, check{name: name, fn: fn})
+}
+
+func (registry *Registry) Run(ctx context.Context) Report {
+	components := make([]ComponentStatus, len(registry.checks))
+
+	var wg sync.WaitGroup
+	for i, c := range registry.checks {
+		wg.Add(1)
+		go func(i int, c check) {
+			defer wg.Done()
+			components[i] = registry.run(ctx, c)
+		}(i, c)
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusUp, Components: components}
+	for _, component := range components {
+		if component.Status != StatusUp {
+			report.Status = StatusDown
+		}
+	}
+	return report
+}
+
+func (registry *Registry) run(ctx context.Context, c check) ComponentStatus {
+	ctx, cancel := context.WithTimeout(ctx, registry.timeout)
+	defer cancel()
+
+	start := time.Now()
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- c.fn(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-errCh:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	status := ComponentStatus{
+		Name:      c.name,
+		Status:    StatusUp,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		status.Status = StatusDown
+		status.Error = err.Error()
+	}
+	return status
+}
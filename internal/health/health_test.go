@@ -0,0 +1,80 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRegistryRun(t *testing.T) {
+	ok := func(context.Context) error { return nil }
+	failing := func(context.Context) error { return errors.New("connection refused") }
+	// hanging ignores its context, so only the registry timeout ends the
+	// check.
+	release := make(chan struct{})
+	defer close(release)
+	hanging := func(context.Context) error { <-release; return nil }
+
+	tests := []struct {
+		name       string
+		checks     map[string]CheckFunc
+		order      []string
+		wantStatus string
+		wantErrors map[string]string
+	}{
+		{
+			name:       "no checks",
+			wantStatus: StatusUp,
+		},
+		{
+			name:       "all up",
+			checks:     map[string]CheckFunc{"database": ok, "mailer": ok},
+			order:      []string{"database", "mailer"},
+			wantStatus: StatusUp,
+		},
+		{
+			name:       "one failing",
+			checks:     map[string]CheckFunc{"database": failing, "mailer": ok},
+			order:      []string{"database", "mailer"},
+			wantStatus: StatusDown,
+			wantErrors: map[string]string{"database": "connection refused"},
+		},
+		{
+			name:       "one timing out",
+			checks:     map[string]CheckFunc{"keys": ok, "database": hanging},
+			order:      []string{"keys", "database"},
+			wantStatus: StatusDown,
+			wantErrors: map[string]string{"database": context.DeadlineExceeded.Error()},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			registry := NewRegistry(50 * time.Millisecond)
+			for _, name := range test.order {
+				registry.Register(name, test.checks[name])
+			}
+
+			report := registry.Run(context.Background())
+			if report.Status != test.wantStatus || report.Healthy() != (test.wantStatus == StatusUp) {
+				t.Errorf("status = %s, want %s", report.Status, test.wantStatus)
+			}
+			if len(report.Components) != len(test.order) {
+				t.Fatalf("got %d components, want %d", len(report.Components), len(test.order))
+			}
+			for i, component := range report.Components {
+				wantErr := test.wantErrors[test.order[i]]
+				wantStatus := StatusUp
+				if wantErr != "" {
+					wantStatus = StatusDown
+				}
+				if component.Name != test.order[i] || component.Status != wantStatus || component.Error != wantErr {
+					t.Errorf("component %d = %+v, want %s %s %q", i, component, test.order[i], wantStatus, wantErr)
+				}
+				if component.LatencyMs < 0 || component.LatencyMs > 1000 {
+					t.Errorf("%s latency = %vms", component.Name, component.LatencyMs)
+				}
+			}
+		})
+	}
+}
@@ -1,36 +1,77 @@
 package helper
 
 import (
+	"context"
+	"fiber-auth-api/internal/metrics"
+	"fiber-auth-api/internal/tracing"
+	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 )
 
-var jwtKey = []byte("secrete-key")
+var (
+	jwtKey   []byte
+	tokenTTL = time.Hour * 24
+)
+
+// ConfigureToken sets the signing key and lifetime used for access tokens.
+func ConfigureToken(secret string, ttl time.Duration) {
+	jwtKey = []byte(secret)
+	tokenTTL = ttl
+}
 
-func HashPassword(password string) (string, error) {
+func HashPassword(ctx context.Context, password string) (string, error) {
+	_, span := tracing.Start(ctx, "helper.HashPassword")
+	start := time.Now()
 	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	metrics.ObservePasswordHash("hash", time.Since(start))
+	tracing.End(span, err)
 	if err != nil {
 		return "", err
 	}
 	return string(hashedBytes), nil
 }
 
-func VerifyPassword(hashedPassword, plainPassword string) error {
+func VerifyPassword(ctx context.Context, hashedPassword, plainPassword string) error {
+	_, span := tracing.Start(ctx, "helper.VerifyPassword")
+	defer span.End()
+
+	start := time.Now()
+	defer func() {
+		metrics.ObservePasswordHash("verify", time.Since(start))
+	}()
 	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(plainPassword))
 }
 
 func CreateToken(userEmail string) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"userEmail": userEmail,
-		"exp":    time.Now().Add(time.Hour * 24).Unix(),
+		"exp":       time.Now().Add(tokenTTL).Unix(),
 	})
 	return token.SignedString(jwtKey)
 }
 
+// CheckTokenKey signs and verifies a throwaway token to confirm the signing
+// key is configured and usable.
+func CheckTokenKey() error {
+	if len(jwtKey) == 0 {
+		return fmt.Errorf("token signing key is not configured")
+	}
+
+	token, err := CreateToken("health-check")
+	if err != nil {
+		return fmt.Errorf("failed to sign token: %w", err)
+	}
+	if _, err := VerifyToken(token); err != nil {
+		return fmt.Errorf("failed to verify token: %w", err)
+	}
+	return nil
+}
+
 func VerifyToken(tokenString string) (jwt.MapClaims, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error){
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 		return jwtKey, nil
 	})
 	if err != nil {
@@ -58,4 +99,4 @@ func ExtractTokenFromHeader(header string) (string, error) {
 func ExtractTokenFromCookie(cookie string) (string, error) {
 	tokenString := cookie[7:]
 	return ExtractToken(tokenString)
-}
\ No newline at end of file
+}
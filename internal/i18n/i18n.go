@@ -0,0 +1,206 @@
+package i18n
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"github.com/go-playground/locales"
+	"github.com/go-playground/locales/de"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/es"
+	"github.com/go-playground/locales/fr"
+	ut "github.com/go-playground/universal-translator"
+	"gopkg.in/yaml.v3"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultLocale is the end of every fallback chain; its catalog must hold
+// every key.
+const DefaultLocale = "en"
+
+//go:embed locales/*.yaml
+var catalogFiles embed.FS
+
+var pluralRules = map[string]locales.PluralRule{
+	"zero":  locales.PluralRuleZero,
+	"one":   locales.PluralRuleOne,
+	"two":   locales.PluralRuleTwo,
+	"few":   locales.PluralRuleFew,
+	"many":  locales.PluralRuleMany,
+	"other": locales.PluralRuleOther,
+}
+
+// Bundle holds the message catalogs of every supported locale.
+type Bundle struct {
+	universal *ut.UniversalTranslator
+	supported []string
+}
+
+// Load builds the bundle from the embedded catalogs. Each catalog is a flat
+// YAML map from message key to text; plural messages map CLDR plural
+// categories (one, other, ...) to text. Parameters are written {0}, {1}, ...
+// and in plural messages {0} is the count.
+func Load() (*Bundle, error) {
+	english := en.New()
+	universal := ut.New(english, english, fr.New(), es.New(), de.New())
+
+	entries, err := catalogFiles.ReadDir("locales")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list message catalogs: %w", err)
+	}
+
+	bundle := &Bundle{universal: universal}
+	for _, entry := range entries {
+		locale := strings.TrimSuffix(entry.Name(), path.Ext(entry.Name()))
+		translator, found := universal.GetTranslator(locale)
+		if !found {
+			return nil, fmt.Errorf("message catalog %s has no matching locale", entry.Name())
+		}
+		if err := loadCatalog(translator, path.Join("locales", entry.Name())); err != nil {
+			return nil, err
+		}
+		bundle.supported = append(bundle.supported, locale)
+	}
+
+	if err := universal.VerifyTranslations(); err != nil {
+		return nil, fmt.Errorf("incomplete message catalog: %w", err)
+	}
+	sort.Strings(bundle.supported)
+	return bundle, nil
+}
+
+func loadCatalog(translator ut.Translator, name string) error {
+	body, err := catalogFiles.ReadFile(name)
+	if err != nil {
+		return fmt.Errorf("failed to read message catalog %s: %w", name, err)
+	}
+
+	var messages map[string]yaml.Node
+	if err := yaml.Unmarshal(body, &messages); err != nil {
+		return fmt.Errorf("invalid message catalog %s: %w", name, err)
+	}
+
+	for key, node := range messages {
+		switch node.Kind {
+		case yaml.ScalarNode:
+			if err := translator.Add(key, node.Value, false); err != nil {
+				return fmt.Errorf("%s: %s: %w", name, key, err)
+			}
+		case yaml.MappingNode:
+			var forms map[string]string
+			if err := node.Decode(&forms); err != nil {
+				return fmt.Errorf("%s: %s: %w", name, key, err)
+			}
+			for category, text := range forms {
+				rule, ok := pluralRules[category]
+				if !ok {
+					return fmt.Errorf("%s: %s: unknown plural category %q", name, key, category)
+				}
+				if err := translator.AddCardinal(key, text, rule, false); err != nil {
+					return fmt.Errorf("%s: %s: %w", name, key, err)
+				}
+			}
+		default:
+			return fmt.Errorf("%s: %s: message must be a string or a map of plural forms", name, key)
+		}
+	}
+	return nil
+}
+
+func (bundle *Bundle) Supported() []string {
+	return bundle.supported
+}
+
+// Localizer translates messages for one request. Lookups walk the
+// negotiated locales in order and end at DefaultLocale.
+type Localizer struct {
+	locale      string
+	translators []ut.Translator
+}
+
+// Localizer returns a localizer for the given language preferences, most
+// preferred first, e.g. the result of ParseAcceptLanguage.
+func (bundle *Bundle) Localizer(preferences ...string) *Localizer {
+	localizer := &Localizer{}
+	seen := make(map[string]bool)
+	for _, preference := range preferences {
+		translator, found := bundle.universal.GetTranslator(preference)
+		if !found || seen[translator.Locale()] {
+			continue
+		}
+		seen[translator.Locale()] = true
+		localizer.translators = append(localizer.translators, translator)
+	}
+
+	fallback := bundle.universal.GetFallback()
+	if !seen[fallback.Locale()] {
+		localizer.translators = append(localizer.translators, fallback)
+	}
+	localizer.locale = localizer.translators[0].Locale()
+	return localizer
+}
+
+// Locale is the locale of the first translator in the chain; it is what
+// Content-Language reports even if single messages fell back.
+func (localizer *Localizer) Locale() string {
+	return strings.ReplaceAll(localizer.locale, "_", "-")
+}
+
+// T translates key with params substituted for {0}, {1}, ... The key itself
+// is returned when no locale has it.
+func (localizer *Localizer) T(key string, params ...string) string {
+	for _, translator := range localizer.translators {
+		if text, ok := translate(translator, key, params); ok {
+			return text
+		}
+	}
+	return key
+}
+
+// C translates the plural message key for count. {0} is the count and
+// params fill {1}, {2}, ...
+func (localizer *Localizer) C(key string, count int, params ...string) string {
+	for _, translator := range localizer.translators {
+		text, err := translator.C(key, float64(count), 0, strconv.Itoa(count))
+		if err != nil {
+			continue
+		}
+		for i, param := range params {
+			text = strings.ReplaceAll(text, "{"+strconv.Itoa(i+1)+"}", param)
+		}
+		return text
+	}
+	return key
+}
+
+// translate guards against catalogs whose text uses more parameters than
+// the caller passed, which universal-translator does not check.
+func translate(translator ut.Translator, key string, params []string) (text string, ok bool) {
+	defer func() {
+		if recover() != nil {
+			text, ok = "", false
+		}
+	}()
+
+	text, err := translator.T(key, params...)
+	return text, err == nil
+}
+
+type localizerKey struct{}
+
+func ContextWithLocalizer(ctx context.Context, localizer *Localizer) context.Context {
+	return context.WithValue(ctx, localizerKey{}, localizer)
+}
+
+// FromContext returns the request's localizer, or nil outside a request
+// that went through the middleware.
+func FromContext(ctx context.Context) *Localizer {
+	if ctx == nil {
+		return nil
+	}
+	localizer, _ := ctx.Value(localizerKey{}).(*Localizer)
+	return localizer
+}
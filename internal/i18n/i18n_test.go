@@ -0,0 +1,79 @@
+package i18n
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		header string
+		want   []string
+	}{
+		{"", nil},
+		{"*", nil},
+		{"fr", []string{"fr"}},
+		{"fr-CA", []string{"fr_ca", "fr"}},
+		{"de;q=0.5, fr-CA, en;q=0.8", []string{"fr_ca", "fr", "en", "de"}},
+		{"es;q=0, fr", []string{"fr"}},
+		{"es;q=high, fr", []string{"fr"}},
+		// Equal qualities keep the order of the header.
+		{"de, fr", []string{"de", "fr"}},
+	}
+	for _, test := range tests {
+		if got := ParseAcceptLanguage(test.header); !slices.Equal(got, test.want) {
+			t.Errorf("ParseAcceptLanguage(%q) = %v, want %v", test.header, got, test.want)
+		}
+	}
+}
+
+func TestLocalizer(t *testing.T) {
+	bundle, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got := bundle.Supported(); !slices.Equal(got, []string{"de", "en", "es", "fr"}) {
+		t.Errorf("Supported = %v", got)
+	}
+
+	tests := []struct {
+		name        string
+		preferences []string
+		wantLocale  string
+		translate   func(localizer *Localizer) string
+		want        string
+	}{
+		{"default locale", nil, "en", func(localizer *Localizer) string {
+			return localizer.T("validation.required", "email")
+		}, "email must be provided"},
+		{"negotiated locale", ParseAcceptLanguage("ja, fr-CA"), "fr", func(localizer *Localizer) string {
+			return localizer.T("validation.required", "email")
+		}, "email doit être renseigné"},
+		{"unsupported locales fall back", []string{"ja"}, "en", func(localizer *Localizer) string {
+			return localizer.T("error.user.not_found")
+		}, "User not found"},
+		{"unknown keys are returned", []string{"de"}, "de", func(localizer *Localizer) string {
+			return localizer.T("no.such.key")
+		}, "no.such.key"},
+		{"missing parameters do not panic", []string{"en"}, "en", func(localizer *Localizer) string {
+			return localizer.T("validation.oneof", "role")
+		}, "validation.oneof"},
+		{"plural one", []string{"en"}, "en", func(localizer *Localizer) string {
+			return localizer.C("detail.request.unknown_fields", 1, "extra")
+		}, "The request contains 1 unknown field: extra"},
+		{"plural other", []string{"de"}, "de", func(localizer *Localizer) string {
+			return localizer.C("detail.request.validation_failed", 3)
+		}, "3 Felder haben die Validierung nicht bestanden"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			localizer := bundle.Localizer(test.preferences...)
+			if got := localizer.Locale(); got != test.wantLocale {
+				t.Errorf("Locale = %q, want %q", got, test.wantLocale)
+			}
+			if got := test.translate(localizer); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
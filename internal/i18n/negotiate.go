@@ -0,0 +1,67 @@
+package i18n
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParseAcceptLanguage returns the languages of an Accept-Language header in
+// order of preference, as universal-translator locale names. Each regional
+// tag is followed by its base language, so fr-CA falls back to fr before
+// the next preference.
+func ParseAcceptLanguage(header string) []string {
+	type preference struct {
+		tag     string
+		quality float64
+	}
+
+	var preferences []preference
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		quality := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		if quality <= 0 {
+			continue
+		}
+		preferences = append(preferences, preference{tag: tag, quality: quality})
+	}
+
+	sort.SliceStable(preferences, func(i, j int) bool {
+		return preferences[i].quality > preferences[j].quality
+	})
+
+	var locales []string
+	for _, preference := range preferences {
+		locale := strings.ToLower(strings.ReplaceAll(preference.tag, "-", "_"))
+		locales = append(locales, locale)
+		if base, _, regional := strings.Cut(locale, "_"); regional {
+			locales = append(locales, base)
+		}
+	}
+	return locales
+}
+
+// Middleware negotiates the response language from Accept-Language and
+// stores the localizer in the request's user context.
+func Middleware(bundle *Bundle) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		localizer := bundle.Localizer(ParseAcceptLanguage(c.Get(fiber.HeaderAcceptLanguage))...)
+		c.SetUserContext(ContextWithLocalizer(c.UserContext(), localizer))
+		c.Set(fiber.HeaderContentLanguage, localizer.Locale())
+		c.Vary(fiber.HeaderAcceptLanguage)
+		return c.Next()
+	}
+}
@@ -0,0 +1,90 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Lifecycle tracks whether the process is draining and runs the registered
+// teardown steps in the order they were added.
+type Lifecycle struct {
+	log      *slog.Logger
+	draining atomic.Bool
+
+	mu    sync.Mutex
+	hooks []hook
+	done  bool
+}
+
+type hook struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+func New(log *slog.Logger) *Lifecycle {
+	return &Lifecycle{log: log}
+}
+
+func (lifecycle *Lifecycle) IsDraining() bool {
+	return lifecycle.draining.Load()
+}
+
+// OnShutdown registers a teardown step. Steps run sequentially in
+// registration order, so register the HTTP server before the things the
+// handlers depend on.
+func (lifecycle *Lifecycle) OnShutdown(name string, fn func(ctx context.Context) error) {
+	lifecycle.mu.Lock()
+	defer lifecycle.mu.Unlock()
+	lifecycle.hooks = append(lifecycle.hooks, hook{name: name, fn: fn})
+}
+
+// Drain marks the process as not ready and then waits for delay, giving load
+// balancers time to stop routing new requests here before listeners close.
+func (lifecycle *Lifecycle) Drain(ctx context.Context, delay time.Duration) {
+	lifecycle.draining.Store(true)
+	if delay <= 0 {
+		return
+	}
+
+	lifecycle.log.Info("Draining before shutdown", "delay", delay)
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
+
+// Shutdown marks the process as draining and runs every teardown step. A
+// failing step is logged and does not stop the ones after it. Shutdown only
+// runs once; later calls return nil.
+func (lifecycle *Lifecycle) Shutdown(ctx context.Context) error {
+	lifecycle.draining.Store(true)
+
+	lifecycle.mu.Lock()
+	if lifecycle.done {
+		lifecycle.mu.Unlock()
+		return nil
+	}
+	lifecycle.done = true
+	hooks := lifecycle.hooks
+	lifecycle.mu.Unlock()
+
+	var errs []error
+	for _, h := range hooks {
+		start := time.Now()
+		if err := h.fn(ctx); err != nil {
+			lifecycle.log.Error("Shutdown step failed", "step", h.name, "error", err)
+			errs = append(errs, fmt.Errorf("%s: %w", h.name, err))
+			continue
+		}
+		lifecycle.log.Info("Shutdown step completed", "step", h.name, "duration", time.Since(start))
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,75 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestLifecycle() *Lifecycle {
+	return New(slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func TestShutdownRunsStepsInOrder(t *testing.T) {
+	lifecycle := newTestLifecycle()
+
+	var ran []string
+	step := func(name string, err error) {
+		lifecycle.OnShutdown(name, func(ctx context.Context) error {
+			if !lifecycle.IsDraining() {
+				t.Errorf("%s ran before the process was marked draining", name)
+			}
+			ran = append(ran, name)
+			return err
+		})
+	}
+	step("http", nil)
+	step("mailer", errors.New("queue not flushed"))
+	step("database", nil)
+
+	err := lifecycle.Shutdown(context.Background())
+	if got := strings.Join(ran, ","); got != "http,mailer,database" {
+		t.Errorf("steps ran as %s, want http,mailer,database", got)
+	}
+	if err == nil || err.Error() != "mailer: queue not flushed" {
+		t.Errorf("Shutdown() = %v, want the mailer failure", err)
+	}
+
+	if err := lifecycle.Shutdown(context.Background()); err != nil || len(ran) != 3 {
+		t.Errorf("second Shutdown() = %v after %d steps, want nil and no steps", err, len(ran))
+	}
+}
+
+func TestDrain(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		delay   time.Duration
+		minWait time.Duration
+	}{
+		{"no delay", context.Background(), 0, 0},
+		{"waits for the delay", context.Background(), 20 * time.Millisecond, 20 * time.Millisecond},
+		{"stops when the context ends", canceled, time.Hour, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			lifecycle := newTestLifecycle()
+			start := time.Now()
+			lifecycle.Drain(test.ctx, test.delay)
+
+			if waited := time.Since(start); waited < test.minWait || waited > test.minWait+time.Second {
+				t.Errorf("Drain waited %s, want about %s", waited, test.minWait)
+			}
+			if !lifecycle.IsDraining() {
+				t.Error("Drain did not mark the process as draining")
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	AccessFormatJSON     = "json"
+	AccessFormatCombined = "combined"
+	AccessFormatLogfmt   = "logfmt"
+
+	combinedTimeLayout = "02/Jan/2006:15:04:05 -0700"
+)
+
+type AccessLogConfig struct {
+	Format string
+	// SuccessSampleRate is the fraction of 2xx responses logged; every
+	// other status is always logged.
+	SuccessSampleRate float64
+}
+
+type AccessEntry struct {
+	Time      time.Time
+	Method    string
+	URI       string
+	Protocol  string
+	Route     string
+	Status    int
+	Bytes     int
+	Latency   time.Duration
+	ClientIP  string
+	UserID    string
+	RequestID string
+	UserAgent string
+	Referer   string
+}
+
+// AccessLogger writes one line per request to the log output, separately
+// from the application log so its format and sampling can differ.
+type AccessLogger struct {
+	format     string
+	sampleRate float64
+	redact     *RedactHandler
+
+	mu     sync.Mutex
+	output io.Writer
+	log    *slog.Logger
+}
+
+func NewAccessLogger(config AccessLogConfig) (*AccessLogger, error) {
+	accessLogger := &AccessLogger{
+		format:     config.Format,
+		sampleRate: config.SuccessSampleRate,
+		output:     logOutput,
+	}
+
+	var handler slog.Handler
+	switch config.Format {
+	case AccessFormatJSON:
+		handler = slog.NewJSONHandler(logOutput, nil)
+	case AccessFormatLogfmt:
+		handler = slog.NewTextHandler(logOutput, nil)
+	case AccessFormatCombined:
+		handler = slog.NewTextHandler(io.Discard, nil)
+	default:
+		return nil, fmt.Errorf("unknown access log format %q", config.Format)
+	}
+
+	redactHandler, err := NewRedactHandler(handler, redactConfig)
+	if err != nil {
+		return nil, err
+	}
+	accessLogger.redact = redactHandler
+	accessLogger.log = slog.New(redactHandler)
+
+	return accessLogger, nil
+}
+
+// Sampled reports whether a response with status should be logged.
+func (accessLogger *AccessLogger) Sampled(status int) bool {
+	if status < 200 || status >= 300 {
+		return true
+	}
+	return accessLogger.sampleRate >= 1 || rand.Float64() < accessLogger.sampleRate
+}
+
+func (accessLogger *AccessLogger) Log(ctx context.Context, entry AccessEntry) {
+	if accessLogger.format == AccessFormatCombined {
+		accessLogger.writeCombined(entry)
+		return
+	}
+
+	level := slog.LevelInfo
+	switch {
+	case entry.Status >= 500:
+		level = slog.LevelError
+	case entry.Status >= 400:
+		level = slog.LevelWarn
+	}
+
+	attrs := []slog.Attr{
+		slog.String("method", entry.Method),
+		slog.String("uri", entry.URI),
+		slog.String("protocol", entry.Protocol),
+		slog.String("route", entry.Route),
+		slog.Int("status", entry.Status),
+		slog.Int("bytes", entry.Bytes),
+		slog.Float64("latency_ms", float64(entry.Latency.Microseconds())/1000),
+		slog.String("client_ip", entry.ClientIP),
+		slog.String("user_agent", entry.UserAgent),
+		slog.String("referer", entry.Referer),
+		slog.String("request_id", entry.RequestID),
+	}
+	if entry.UserID != "" {
+		attrs = append(attrs, slog.String("user_id", entry.UserID))
+	}
+
+	accessLogger.log.LogAttrs(ctx, level, "request", attrs...)
+}
+
+// writeCombined writes the Apache combined format followed by the latency
+// in seconds and the request id, which log processors treat as trailing
+// fields.
+func (accessLogger *AccessLogger) writeCombined(entry AccessEntry) {
+	bytes := "-"
+	if entry.Bytes > 0 {
+		bytes = strconv.Itoa(entry.Bytes)
+	}
+
+	line := fmt.Sprintf("%s - %s [%s] \"%s %s %s\" %d %s %q %q %.6f %s\n",
+		entry.ClientIP,
+		orDash(entry.UserID),
+		entry.Time.Format(combinedTimeLayout),
+		entry.Method,
+		accessLogger.redact.scrub(entry.URI),
+		entry.Protocol,
+		entry.Status,
+		bytes,
+		orDash(entry.Referer),
+		orDash(entry.UserAgent),
+		entry.Latency.Seconds(),
+		orDash(entry.RequestID),
+	)
+
+	accessLogger.mu.Lock()
+	defer accessLogger.mu.Unlock()
+	_, _ = io.WriteString(accessLogger.output, line)
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
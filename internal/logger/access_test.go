@@ -0,0 +1,98 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAccessLoggerFormats(t *testing.T) {
+	entry := AccessEntry{
+		Time:      time.Date(2024, time.March, 9, 14, 5, 7, 0, time.FixedZone("", 3600)),
+		Method:    "GET",
+		URI:       "/api/v1/users?email=jane@example.com",
+		Protocol:  "HTTP/1.1",
+		Route:     "/api/v1/users",
+		Status:    404,
+		Bytes:     0,
+		Latency:   1500 * time.Microsecond,
+		ClientIP:  "203.0.113.7",
+		RequestID: "req-1",
+		UserAgent: "curl/8.0",
+	}
+
+	tests := []struct {
+		format  string
+		want    []string
+		notWant []string
+	}{
+		{
+			format:  AccessFormatJSON,
+			want:    []string{`"level":"WARN"`, `"status":404`, `"bytes":0`, `"latency_ms":1.5`, `"client_ip":"203.0.113.7"`, `"request_id":"req-1"`},
+			notWant: []string{"jane@example.com", "user_id"},
+		},
+		{
+			format:  AccessFormatLogfmt,
+			want:    []string{"level=WARN", "method=GET", "status=404", "route=/api/v1/users", "latency_ms=1.5"},
+			notWant: []string{"jane@example.com"},
+		},
+		{
+			format:  AccessFormatCombined,
+			want:    []string{`203.0.113.7 - - [09/Mar/2024:14:05:07 +0100] "GET /api/v1/users?email=[REDACTED] HTTP/1.1" 404 - "-" "curl/8.0" 0.001500 req-1` + "\n"},
+			notWant: []string{"jane@example.com"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.format, func(t *testing.T) {
+			var out bytes.Buffer
+			previous := logOutput
+			logOutput = &out
+			t.Cleanup(func() { logOutput = previous })
+
+			accessLogger, err := NewAccessLogger(AccessLogConfig{Format: test.format, SuccessSampleRate: 1})
+			if err != nil {
+				t.Fatalf("NewAccessLogger: %v", err)
+			}
+			accessLogger.Log(context.Background(), entry)
+
+			for _, want := range test.want {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("output %q does not contain %q", out.String(), want)
+				}
+			}
+			for _, notWant := range test.notWant {
+				if strings.Contains(out.String(), notWant) {
+					t.Errorf("output %q contains %q", out.String(), notWant)
+				}
+			}
+		})
+	}
+
+	if _, err := NewAccessLogger(AccessLogConfig{Format: "xml"}); err == nil {
+		t.Error("NewAccessLogger accepted an unknown format")
+	}
+}
+
+func TestAccessLoggerSampled(t *testing.T) {
+	tests := []struct {
+		rate   float64
+		status int
+		want   bool
+	}{
+		{0, 200, false},
+		{0, 204, false},
+		{0, 101, true},
+		{0, 304, true},
+		{0, 404, true},
+		{0, 503, true},
+		{1, 200, true},
+	}
+	for _, test := range tests {
+		accessLogger := &AccessLogger{sampleRate: test.rate}
+		if got := accessLogger.Sampled(test.status); got != test.want {
+			t.Errorf("rate %v: Sampled(%d) = %v, want %v", test.rate, test.status, got, test.want)
+		}
+	}
+}
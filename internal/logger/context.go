@@ -0,0 +1,135 @@
+package logger
+
+import (
+	"context"
+	"go.opentelemetry.io/otel/trace"
+	"log/slog"
+	"sync"
+)
+
+type requestFieldsKey struct{}
+
+// RequestFields holds the per-request values ContextHandler adds to every
+// record logged with the request's context. The route is resolved lazily
+// because it is only known once the router has matched the request.
+type RequestFields struct {
+	mu        sync.RWMutex
+	requestID string
+	userID    string
+	route     string
+	routeFunc func() string
+}
+
+func NewRequestFields(requestID string) *RequestFields {
+	return &RequestFields{requestID: requestID}
+}
+
+func ContextWithRequestFields(ctx context.Context, fields *RequestFields) context.Context {
+	return context.WithValue(ctx, requestFieldsKey{}, fields)
+}
+
+func RequestFieldsFromContext(ctx context.Context) *RequestFields {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(requestFieldsKey{}).(*RequestFields)
+	return fields
+}
+
+func RequestIDFromContext(ctx context.Context) string {
+	if fields := RequestFieldsFromContext(ctx); fields != nil {
+		return fields.requestID
+	}
+	return ""
+}
+
+func UserIDFromContext(ctx context.Context) string {
+	fields := RequestFieldsFromContext(ctx)
+	if fields == nil {
+		return ""
+	}
+	fields.mu.RLock()
+	defer fields.mu.RUnlock()
+	return fields.userID
+}
+
+// SetUserID records the authenticated user on the request in ctx. It is a
+// no-op outside a request.
+func SetUserID(ctx context.Context, userID string) {
+	fields := RequestFieldsFromContext(ctx)
+	if fields == nil {
+		return
+	}
+	fields.mu.Lock()
+	defer fields.mu.Unlock()
+	fields.userID = userID
+}
+
+func (fields *RequestFields) SetRouteFunc(routeFunc func() string) {
+	fields.mu.Lock()
+	defer fields.mu.Unlock()
+	fields.routeFunc = routeFunc
+}
+
+// SetRoute fixes the route and drops the lazy lookup, so records logged after
+// the request finished never touch a recycled request context.
+func (fields *RequestFields) SetRoute(route string) {
+	fields.mu.Lock()
+	defer fields.mu.Unlock()
+	fields.route = route
+	fields.routeFunc = nil
+}
+
+func (fields *RequestFields) attrs() []slog.Attr {
+	fields.mu.RLock()
+	defer fields.mu.RUnlock()
+
+	attrs := []slog.Attr{slog.String("request_id", fields.requestID)}
+	if fields.userID != "" {
+		attrs = append(attrs, slog.String("user_id", fields.userID))
+	}
+
+	route := fields.route
+	if fields.routeFunc != nil {
+		route = fields.routeFunc()
+	}
+	if route != "" {
+		attrs = append(attrs, slog.String("route", route))
+	}
+	return attrs
+}
+
+// ContextHandler adds request_id, user_id, route and trace_id from the
+// record's context to every record before passing it on.
+type ContextHandler struct {
+	handler slog.Handler
+}
+
+func NewContextHandler(handler slog.Handler) *ContextHandler {
+	return &ContextHandler{handler: handler}
+}
+
+func (h *ContextHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+func (h *ContextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if fields := RequestFieldsFromContext(ctx); fields != nil {
+		record.AddAttrs(fields.attrs()...)
+	}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		record.AddAttrs(
+			slog.String("trace_id", spanContext.TraceID().String()),
+			slog.String("span_id", spanContext.SpanID().String()),
+		)
+	}
+	return h.handler.Handle(ctx, record)
+}
+
+func (h *ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ContextHandler{handler: h.handler.WithAttrs(attrs)}
+}
+
+func (h *ContextHandler) WithGroup(name string) slog.Handler {
+	return &ContextHandler{handler: h.handler.WithGroup(name)}
+}
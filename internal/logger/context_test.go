@@ -0,0 +1,100 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestContextHandler(t *testing.T) {
+	spanContext := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35},
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa},
+		TraceFlags: trace.FlagsSampled,
+	})
+
+	tests := []struct {
+		name string
+		ctx  func() context.Context
+		want map[string]string
+	}{
+		{
+			name: "outside a request",
+			ctx:  context.Background,
+			want: map[string]string{},
+		},
+		{
+			name: "request before routing",
+			ctx: func() context.Context {
+				return ContextWithRequestFields(context.Background(), NewRequestFields("req-1"))
+			},
+			want: map[string]string{"request_id": "req-1"},
+		},
+		{
+			name: "authenticated request with a resolved route",
+			ctx: func() context.Context {
+				fields := NewRequestFields("req-2")
+				fields.SetRouteFunc(func() string { return "/lazy" })
+				ctx := ContextWithRequestFields(context.Background(), fields)
+				SetUserID(ctx, "user-9")
+				fields.SetRoute("/api/v1/users/:id")
+				return ctx
+			},
+			want: map[string]string{"request_id": "req-2", "user_id": "user-9", "route": "/api/v1/users/:id"},
+		},
+		{
+			name: "route resolved lazily",
+			ctx: func() context.Context {
+				fields := NewRequestFields("req-3")
+				fields.SetRouteFunc(func() string { return "/lazy" })
+				return ContextWithRequestFields(context.Background(), fields)
+			},
+			want: map[string]string{"request_id": "req-3", "route": "/lazy"},
+		},
+		{
+			name: "traced request",
+			ctx: func() context.Context {
+				ctx := trace.ContextWithSpanContext(context.Background(), spanContext)
+				return ContextWithRequestFields(ctx, NewRequestFields("req-4"))
+			},
+			want: map[string]string{
+				"request_id": "req-4",
+				"trace_id":   spanContext.TraceID().String(),
+				"span_id":    spanContext.SpanID().String(),
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var out bytes.Buffer
+			log := slog.New(NewContextHandler(slog.NewJSONHandler(&out, nil))).With("component", "test")
+			log.InfoContext(test.ctx(), "hello")
+
+			var record map[string]any
+			if err := json.Unmarshal(out.Bytes(), &record); err != nil {
+				t.Fatalf("Unmarshal(%s): %v", out.String(), err)
+			}
+			if record["component"] != "test" {
+				t.Errorf("record lost the handler attributes: %s", out.String())
+			}
+			for _, key := range []string{"request_id", "user_id", "route", "trace_id", "span_id"} {
+				got, _ := record[key].(string)
+				if got != test.want[key] {
+					t.Errorf("%s = %q, want %q", key, got, test.want[key])
+				}
+			}
+		})
+	}
+}
+
+func TestSetUserIDOutsideRequest(t *testing.T) {
+	ctx := context.Background()
+	SetUserID(ctx, "user-1")
+	if got := UserIDFromContext(ctx); got != "" || RequestIDFromContext(ctx) != "" {
+		t.Errorf("UserIDFromContext = %q outside a request", got)
+	}
+}
@@ -0,0 +1,128 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"sync"
+)
+
+var (
+	globalLevel = new(slog.LevelVar)
+
+	packageLevelsMu sync.RWMutex
+	packageLevels   = make(map[string]slog.Level)
+
+	knownPackagesMu sync.Mutex
+	knownPackages   = make(map[string]struct{})
+)
+
+// Level returns the global minimum level.
+func Level() slog.Level {
+	return globalLevel.Level()
+}
+
+func SetLevel(level slog.Level) {
+	globalLevel.Set(level)
+}
+
+// PackageLevels returns the packages whose level overrides the global one.
+func PackageLevels() map[string]slog.Level {
+	packageLevelsMu.RLock()
+	defer packageLevelsMu.RUnlock()
+
+	levels := make(map[string]slog.Level, len(packageLevels))
+	for name, level := range packageLevels {
+		levels[name] = level
+	}
+	return levels
+}
+
+func SetPackageLevel(name string, level slog.Level) {
+	packageLevelsMu.Lock()
+	defer packageLevelsMu.Unlock()
+	packageLevels[name] = level
+}
+
+// ResetPackageLevel makes the package follow the global level again.
+func ResetPackageLevel(name string) {
+	packageLevelsMu.Lock()
+	defer packageLevelsMu.Unlock()
+	delete(packageLevels, name)
+}
+
+// ReplacePackageLevels drops every override and installs levels instead.
+func ReplacePackageLevels(levels map[string]slog.Level) {
+	packageLevelsMu.Lock()
+	defer packageLevelsMu.Unlock()
+
+	packageLevels = make(map[string]slog.Level, len(levels))
+	for name, level := range levels {
+		packageLevels[name] = level
+	}
+}
+
+func effectiveLevel(name string) slog.Level {
+	if name != "" {
+		packageLevelsMu.RLock()
+		level, ok := packageLevels[name]
+		packageLevelsMu.RUnlock()
+		if ok {
+			return level
+		}
+	}
+	return globalLevel.Level()
+}
+
+// sinkHandler gates one sink: at the sink's own level when it has one, so
+// it can get more or less detail than the rest, or else at the runtime
+// level of the package logging through it.
+type sinkHandler struct {
+	handler slog.Handler
+	level   slog.Leveler
+	name    string
+}
+
+func (h *sinkHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if h.level != nil {
+		return level >= h.level.Level()
+	}
+	return level >= effectiveLevel(h.name)
+}
+
+func (h *sinkHandler) Handle(ctx context.Context, record slog.Record) error {
+	return h.handler.Handle(ctx, record)
+}
+
+func (h *sinkHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &sinkHandler{handler: h.handler.WithAttrs(attrs), level: h.level, name: h.name}
+}
+
+func (h *sinkHandler) WithGroup(name string) slog.Handler {
+	return &sinkHandler{handler: h.handler.WithGroup(name), level: h.level, name: h.name}
+}
+
+// ForPackage returns a logger whose level can be changed independently with
+// SetPackageLevel. It must be called after InitializeLogger.
+func ForPackage(name string) *slog.Logger {
+	knownPackagesMu.Lock()
+	knownPackages[name] = struct{}{}
+	knownPackagesMu.Unlock()
+
+	GetLogger()
+	return slog.New(handlerFor(name))
+}
+
+// KnownPackages lists the package names handed to ForPackage, so the admin
+// API can show which overrides are meaningful.
+func KnownPackages() []string {
+	knownPackagesMu.Lock()
+	defer knownPackagesMu.Unlock()
+
+	names := make([]string, 0, len(knownPackages))
+	for name := range knownPackages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
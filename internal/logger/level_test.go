@@ -0,0 +1,133 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSinkLevels(t *testing.T) {
+	dir := t.TempDir()
+	follow := filepath.Join(dir, "follow.log")
+	debug := filepath.Join(dir, "debug.log")
+	warn := filepath.Join(dir, "warn.log")
+
+	err := InitializeLogger(SlogLogConfig{
+		Level:         slog.LevelInfo,
+		PackageLevels: map[string]slog.Level{"chatty": slog.LevelDebug},
+		Sinks: []SinkConfig{
+			{Output: follow},
+			{Output: debug, Level: slog.LevelDebug},
+			{Output: warn, Level: slog.LevelWarn},
+		},
+	})
+	if err != nil {
+		t.Fatalf("InitializeLogger: %v", err)
+	}
+	t.Cleanup(func() {
+		Close()
+		InitializeLogger()
+	})
+
+	tests := []struct {
+		message   string
+		logger    *slog.Logger
+		level     slog.Level
+		wantSinks []string
+	}{
+		{"root debug", GetLogger(), slog.LevelDebug, []string{debug}},
+		{"root info", GetLogger(), slog.LevelInfo, []string{follow, debug}},
+		{"root error", GetLogger(), slog.LevelError, []string{follow, debug, warn}},
+		{"quiet debug", ForPackage("quiet"), slog.LevelDebug, []string{debug}},
+		{"chatty debug", ForPackage("chatty"), slog.LevelDebug, []string{follow, debug}},
+		{"chatty warn", ForPackage("chatty"), slog.LevelWarn, []string{follow, debug, warn}},
+	}
+	for _, test := range tests {
+		test.logger.Log(context.Background(), test.level, test.message)
+	}
+	if err := Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	contents := make(map[string]string)
+	for _, path := range []string{follow, debug, warn} {
+		body, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("ReadFile(%s): %v", path, err)
+		}
+		contents[path] = string(body)
+	}
+
+	for _, test := range tests {
+		for _, path := range []string{follow, debug, warn} {
+			want := false
+			for _, sink := range test.wantSinks {
+				want = want || sink == path
+			}
+			if got := strings.Contains(contents[path], `"`+test.message+`"`); got != want {
+				t.Errorf("%q in %s = %v, want %v", test.message, filepath.Base(path), got, want)
+			}
+		}
+	}
+}
+
+func TestRuntimeLevels(t *testing.T) {
+	previousLevel, previousPackages := Level(), PackageLevels()
+	t.Cleanup(func() {
+		SetLevel(previousLevel)
+		ReplacePackageLevels(previousPackages)
+	})
+	SetLevel(slog.LevelInfo)
+	ReplacePackageLevels(nil)
+
+	// Loggers created before a change must follow it.
+	root := GetLogger()
+	repositories := ForPackage("repositories")
+	handlers := ForPackage("handlers")
+
+	tests := []struct {
+		name             string
+		change           func()
+		wantRoot         slog.Level
+		wantRepositories slog.Level
+		wantHandlers     slog.Level
+	}{
+		{"initial", func() {}, slog.LevelInfo, slog.LevelInfo, slog.LevelInfo},
+		{"global level", func() { SetLevel(slog.LevelWarn) }, slog.LevelWarn, slog.LevelWarn, slog.LevelWarn},
+		{"package override", func() { SetPackageLevel("repositories", slog.LevelDebug) }, slog.LevelWarn, slog.LevelDebug, slog.LevelWarn},
+		{"override survives global change", func() { SetLevel(slog.LevelError) }, slog.LevelError, slog.LevelDebug, slog.LevelError},
+		{"reset override", func() { ResetPackageLevel("repositories") }, slog.LevelError, slog.LevelError, slog.LevelError},
+		{
+			name:             "replace overrides",
+			change:           func() { ReplacePackageLevels(map[string]slog.Level{"handlers": slog.LevelDebug}) },
+			wantRoot:         slog.LevelError,
+			wantRepositories: slog.LevelError,
+			wantHandlers:     slog.LevelDebug,
+		},
+	}
+	for _, test := range tests {
+		test.change()
+		for name, check := range map[string]struct {
+			logger *slog.Logger
+			want   slog.Level
+		}{
+			"root":         {root, test.wantRoot},
+			"repositories": {repositories, test.wantRepositories},
+			"handlers":     {handlers, test.wantHandlers},
+		} {
+			ctx := context.Background()
+			if !check.logger.Enabled(ctx, check.want) || check.logger.Enabled(ctx, check.want-1) {
+				t.Errorf("%s: %s logger is not enabled from exactly %s", test.name, name, check.want)
+			}
+		}
+	}
+
+	known := KnownPackages()
+	if !slices.Contains(known, "repositories") || !slices.Contains(known, "handlers") || !slices.IsSorted(known) {
+		t.Errorf("KnownPackages() = %v", known)
+	}
+}
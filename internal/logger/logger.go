@@ -1,22 +1,56 @@
 package logger
 
 import (
+	"errors"
+	"io"
 	"log/slog"
+	"math"
 	"os"
+	"syscall"
+)
+
+const (
+	OutputStdout = "stdout"
+	OutputStderr = "stderr"
 )
 
 var (
 	globalSlogLogger *slog.Logger
+	logOutput        io.Writer = os.Stdout
+	logOutputs       []io.Writer
+	redactConfig     RedactConfig
+
+	// sinkHandlers and redactTemplate are what handlerFor assembles the
+	// handler of each logger from.
+	sinkHandlers   []*sinkHandler
+	redactTemplate *RedactHandler
 )
 
 type SlogLogConfig struct {
-	Level slog.Level
-	JSON  bool
+	// Level and PackageLevels seed the runtime levels; see SetLevel and
+	// SetPackageLevel.
+	Level         slog.Level
+	PackageLevels map[string]slog.Level
+	JSON          bool
+	Redact        RedactConfig
+	// Sinks replace the default stdout destination. Each sink has its own
+	// format and level.
+	Sinks []SinkConfig
 }
 
-func InitializeLogger(config ...SlogLogConfig) {
+type SinkConfig struct {
+	// Output is stdout, stderr or a file path.
+	Output string
+	JSON   bool
+	// Level is the sink's fixed minimum level, applied instead of the
+	// runtime global and package levels; nil follows those.
+	Level slog.Leveler
+	// File holds rotation settings for file outputs; its Path is taken
+	// from Output.
+	File FileConfig
+}
 
-	var logHandler slog.Handler
+func InitializeLogger(config ...SlogLogConfig) error {
 
 	loggerConfig := SlogLogConfig{
 		Level: slog.LevelInfo,
@@ -27,20 +61,89 @@ func InitializeLogger(config ...SlogLogConfig) {
 		loggerConfig = config[0]
 	}
 
-	if loggerConfig.JSON {
-		logHandler = slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-			Level: loggerConfig.Level,
-		})
-	} else {
-		logHandler = slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
-			Level: loggerConfig.Level,
-		})
+	sinks := loggerConfig.Sinks
+	if len(sinks) == 0 {
+		sinks = []SinkConfig{{Output: OutputStdout, JSON: loggerConfig.JSON}}
+	}
+
+	var handlers []*sinkHandler
+	var outputs []io.Writer
+	for _, sink := range sinks {
+		output, err := openOutput(sink)
+		if err != nil {
+			closeOutputs(outputs)
+			return err
+		}
+		outputs = append(outputs, output)
+
+		// sinkHandler decides what reaches the sink; the format handler
+		// writes whatever it is given.
+		options := &slog.HandlerOptions{Level: slog.Level(math.MinInt)}
+		var handler slog.Handler = slog.NewTextHandler(output, options)
+		if sink.JSON {
+			handler = slog.NewJSONHandler(output, options)
+		}
+		handlers = append(handlers, &sinkHandler{handler: handler, level: sink.Level})
+	}
+
+	redactHandler, err := NewRedactHandler(nil, loggerConfig.Redact)
+	if err != nil {
+		closeOutputs(outputs)
+		return err
 	}
 
-	globalSlogLogger = slog.New(logHandler)
+	previousOutputs := logOutputs
+	logOutputs = outputs
+	// The access log writes to the first sink.
+	logOutput = outputs[0]
+	redactConfig = loggerConfig.Redact
+	sinkHandlers, redactTemplate = handlers, redactHandler
+	SetLevel(loggerConfig.Level)
+	ReplacePackageLevels(loggerConfig.PackageLevels)
+	globalSlogLogger = slog.New(handlerFor(""))
 
 	slog.SetDefault(globalSlogLogger)
 
+	closeOutputs(previousOutputs)
+
+	return nil
+}
+
+// handlerFor assembles the handler of the logger of package name, or of the
+// root logger for "". Redaction runs before the request fields are added,
+// so ids from the request context are never masked in allowlist mode.
+func handlerFor(name string) slog.Handler {
+	handlers := make([]slog.Handler, len(sinkHandlers))
+	for i, sink := range sinkHandlers {
+		handlers[i] = &sinkHandler{handler: sink.handler, level: sink.level, name: name}
+	}
+
+	handler := handlers[0]
+	if len(handlers) > 1 {
+		handler = NewMultiHandler(handlers...)
+	}
+	return redactTemplate.withHandler(NewContextHandler(handler))
+}
+
+func openOutput(sink SinkConfig) (io.Writer, error) {
+	switch sink.Output {
+	case OutputStdout, "":
+		return os.Stdout, nil
+	case OutputStderr:
+		return os.Stderr, nil
+	default:
+		fileConfig := sink.File
+		fileConfig.Path = sink.Output
+		return OpenRotatingFile(fileConfig)
+	}
+}
+
+func closeOutputs(outputs []io.Writer) {
+	for _, output := range outputs {
+		if closer, ok := output.(io.Closer); ok && output != os.Stdout && output != os.Stderr {
+			closer.Close()
+		}
+	}
 }
 
 func GetLogger() *slog.Logger {
@@ -50,6 +153,27 @@ func GetLogger() *slog.Logger {
 	return globalSlogLogger
 }
 
+// Flush syncs every log output. Outputs that cannot be synced, such as pipes
+// and terminals, are skipped.
+func Flush() error {
+	var errs []error
+	for _, output := range logOutputs {
+		if syncer, ok := output.(interface{ Sync() error }); ok {
+			err := syncer.Sync()
+			if !errors.Is(err, syscall.EINVAL) && !errors.Is(err, syscall.ENOTSUP) {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close closes log files; records logged afterwards to a file sink are
+// dropped.
+func Close() {
+	closeOutputs(logOutputs)
+}
+
 func Debug(msg string, args ...any) {
 	globalSlogLogger.Debug(msg, args...)
 }
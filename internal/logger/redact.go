@@ -0,0 +1,202 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+const RedactedValue = "[REDACTED]"
+
+// DefaultRedactKeys are attribute keys whose values are always masked.
+// Keys are compared case-insensitively with "-" treated as "_".
+var DefaultRedactKeys = []string{
+	"password",
+	"password_hash",
+	"new_password",
+	"token",
+	"access_token",
+	"refresh_token",
+	"authorization",
+	"cookie",
+	"set_cookie",
+	"secret",
+	"jwt_secret",
+	"email",
+}
+
+// DefaultAllowedKeys are the attribute keys logged as-is in allowlist mode.
+// Everything the application itself logs is listed here; anything else has
+// its value masked.
+var DefaultAllowedKeys = []string{
+	"request_id",
+	"user_id",
+	"route",
+	"trace_id",
+	"span_id",
+	"error",
+	"components",
+	"version",
+	"name",
+	"step",
+	"delay",
+	"duration",
+	"timeout",
+	"method",
+	"uri",
+	"protocol",
+	"status",
+	"bytes",
+	"latency_ms",
+	"client_ip",
+	"user_agent",
+	"referer",
+	"level",
+	"packages",
+	"event_type",
+	"outcome",
+	"broken_at",
+	"reason",
+	"code",
+}
+
+type redactPattern struct {
+	re          *regexp.Regexp
+	replacement string
+}
+
+var defaultRedactPatterns = []redactPattern{
+	// Postgres constraint details, e.g. Key (email)=(jane@example.com).
+	{regexp.MustCompile(`(Key \([^)]*\)=\()[^)]*(\))`), "${1}" + RedactedValue + "${2}"},
+	{regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9\-._~+/]+=*`), "${1}" + RedactedValue},
+	{regexp.MustCompile(`eyJ[A-Za-z0-9_-]*\.[A-Za-z0-9_-]*\.[A-Za-z0-9_-]*`), RedactedValue},
+	{regexp.MustCompile(`\$2[aby]?\$\d{2}\$[./A-Za-z0-9]{53}`), RedactedValue},
+	{regexp.MustCompile(`(?i)\b(password|passwd|pwd|secret|token)=[^\s&]+`), "${1}=" + RedactedValue},
+	{regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`), RedactedValue},
+}
+
+type RedactConfig struct {
+	// Keys and Patterns extend the defaults; they never replace them.
+	Keys     []string
+	Patterns []string
+	// Allowlist masks the value of every attribute whose key is not in
+	// DefaultAllowedKeys or AllowedKeys.
+	Allowlist   bool
+	AllowedKeys []string
+}
+
+// RedactHandler masks sensitive attribute values and scrubs known secrets
+// out of messages and string values before passing records on.
+type RedactHandler struct {
+	handler     slog.Handler
+	keys        map[string]bool
+	patterns    []redactPattern
+	allowlist   bool
+	allowedKeys map[string]bool
+}
+
+func NewRedactHandler(handler slog.Handler, config RedactConfig) (*RedactHandler, error) {
+	redactHandler := &RedactHandler{
+		handler:     handler,
+		keys:        make(map[string]bool),
+		patterns:    append([]redactPattern(nil), defaultRedactPatterns...),
+		allowlist:   config.Allowlist,
+		allowedKeys: make(map[string]bool),
+	}
+
+	for _, key := range append(append([]string(nil), DefaultRedactKeys...), config.Keys...) {
+		redactHandler.keys[normalizeKey(key)] = true
+	}
+	for _, key := range append(append([]string(nil), DefaultAllowedKeys...), config.AllowedKeys...) {
+		redactHandler.allowedKeys[normalizeKey(key)] = true
+	}
+	for _, pattern := range config.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", pattern, err)
+		}
+		redactHandler.patterns = append(redactHandler.patterns, redactPattern{re: re, replacement: RedactedValue})
+	}
+
+	return redactHandler, nil
+}
+
+// withHandler returns a copy of h passing records on to handler.
+func (h *RedactHandler) withHandler(handler slog.Handler) *RedactHandler {
+	clone := *h
+	clone.handler = handler
+	return &clone
+}
+
+func normalizeKey(key string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(key)), "-", "_")
+}
+
+func (h *RedactHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+func (h *RedactHandler) Handle(ctx context.Context, record slog.Record) error {
+	redacted := slog.NewRecord(record.Time, record.Level, h.scrub(record.Message), record.PC)
+	record.Attrs(func(attr slog.Attr) bool {
+		redacted.AddAttrs(h.redactAttr(attr))
+		return true
+	})
+	return h.handler.Handle(ctx, redacted)
+}
+
+func (h *RedactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redactedAttrs := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		redactedAttrs[i] = h.redactAttr(attr)
+	}
+	clone := *h
+	clone.handler = h.handler.WithAttrs(redactedAttrs)
+	return &clone
+}
+
+func (h *RedactHandler) WithGroup(name string) slog.Handler {
+	clone := *h
+	clone.handler = h.handler.WithGroup(name)
+	return &clone
+}
+
+func (h *RedactHandler) redactAttr(attr slog.Attr) slog.Attr {
+	attr.Value = attr.Value.Resolve()
+
+	if attr.Value.Kind() == slog.KindGroup {
+		group := attr.Value.Group()
+		redactedGroup := make([]slog.Attr, len(group))
+		for i, member := range group {
+			redactedGroup[i] = h.redactAttr(member)
+		}
+		return slog.Attr{Key: attr.Key, Value: slog.GroupValue(redactedGroup...)}
+	}
+
+	key := normalizeKey(attr.Key)
+	if h.keys[key] || (h.allowlist && !h.allowedKeys[key]) {
+		return slog.String(attr.Key, RedactedValue)
+	}
+
+	switch attr.Value.Kind() {
+	case slog.KindString:
+		return slog.String(attr.Key, h.scrub(attr.Value.String()))
+	case slog.KindAny:
+		switch value := attr.Value.Any().(type) {
+		case error:
+			return slog.String(attr.Key, h.scrub(value.Error()))
+		case fmt.Stringer:
+			return slog.String(attr.Key, h.scrub(value.String()))
+		}
+	}
+	return attr
+}
+
+func (h *RedactHandler) scrub(value string) string {
+	for _, pattern := range h.patterns {
+		value = pattern.re.ReplaceAllString(value, pattern.replacement)
+	}
+	return value
+}
@@ -0,0 +1,107 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestRedactHandler(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  RedactConfig
+		message string
+		attrs   []any
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "default keys ignore case and dashes",
+			attrs:   []any{"Password", "hunter2", "Set-Cookie", "session=abc", "user_id", "42"},
+			want:    []string{`"Password":"[REDACTED]"`, `"Set-Cookie":"[REDACTED]"`, `"user_id":"42"`},
+			notWant: []string{"hunter2", "session=abc"},
+		},
+		{
+			name:    "configured keys extend the defaults",
+			config:  RedactConfig{Keys: []string{"ssn"}},
+			attrs:   []any{"ssn", "123-45-6789", "token", "t"},
+			want:    []string{`"ssn":"[REDACTED]"`, `"token":"[REDACTED]"`},
+			notWant: []string{"123-45-6789"},
+		},
+		{
+			name:    "patterns scrub messages and values",
+			message: "login for jane@example.com",
+			attrs:   []any{"header", "Bearer abc.def", "query", "a=1&password=pw&b=2"},
+			want:    []string{`"msg":"login for [REDACTED]"`, `"header":"Bearer [REDACTED]"`, `"query":"a=1&password=[REDACTED]&b=2"`},
+			notWant: []string{"jane@", "abc.def", "=pw"},
+		},
+		{
+			name:    "errors are scrubbed",
+			attrs:   []any{"error", errors.New(`duplicate key: Key (email)=(jane@example.com) already exists`)},
+			want:    []string{`Key (email)=([REDACTED]) already exists`},
+			notWant: []string{"jane@"},
+		},
+		{
+			name:    "groups are redacted member by member",
+			attrs:   []any{slog.Group("request", "secret", "s3", "route", "/users")},
+			want:    []string{`"request":{"secret":"[REDACTED]","route":"/users"}`},
+			notWant: []string{"s3"},
+		},
+		{
+			name:    "configured patterns",
+			config:  RedactConfig{Patterns: []string{`sk_live_[a-z0-9]+`}},
+			attrs:   []any{"note", "key sk_live_abc123 used"},
+			want:    []string{`"note":"key [REDACTED] used"`},
+			notWant: []string{"sk_live_abc123"},
+		},
+		{
+			name:    "allowlist masks unknown keys",
+			config:  RedactConfig{Allowlist: true, AllowedKeys: []string{"tenant"}},
+			attrs:   []any{"status", 200, "tenant", "acme", "shoe_size", "forty-four"},
+			want:    []string{`"status":200`, `"tenant":"acme"`, `"shoe_size":"[REDACTED]"`},
+			notWant: []string{"forty-four"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var output bytes.Buffer
+			handler, err := NewRedactHandler(slog.NewJSONHandler(&output, nil), test.config)
+			if err != nil {
+				t.Fatalf("NewRedactHandler: %v", err)
+			}
+			slog.New(handler).Info(test.message, test.attrs...)
+
+			for _, want := range test.want {
+				if !strings.Contains(output.String(), want) {
+					t.Errorf("output %s does not contain %s", output.String(), want)
+				}
+			}
+			for _, notWant := range test.notWant {
+				if strings.Contains(output.String(), notWant) {
+					t.Errorf("output %s contains %s", output.String(), notWant)
+				}
+			}
+		})
+	}
+}
+
+func TestRedactHandlerWithAttrs(t *testing.T) {
+	var output bytes.Buffer
+	handler, err := NewRedactHandler(slog.NewJSONHandler(&output, nil), RedactConfig{})
+	if err != nil {
+		t.Fatalf("NewRedactHandler: %v", err)
+	}
+	slog.New(handler).With("email", "jane@example.com").Info("hello")
+
+	if strings.Contains(output.String(), "jane@") {
+		t.Errorf("attrs added with With were not redacted: %s", output.String())
+	}
+}
+
+func TestNewRedactHandlerRejectsInvalidPatterns(t *testing.T) {
+	if _, err := NewRedactHandler(nil, RedactConfig{Patterns: []string{"("}}); err == nil {
+		t.Error("NewRedactHandler accepted an invalid pattern")
+	}
+}
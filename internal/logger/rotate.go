@@ -0,0 +1,317 @@
+package logger
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const backupTimeLayout = "20060102T150405.000"
+
+// rotationRetryInterval is how long a file keeps growing after a failed
+// rotation before the next attempt.
+const rotationRetryInterval = time.Minute
+
+// renameFile is os.Rename, replaced in tests to make rotation fail.
+var renameFile = os.Rename
+
+type FileConfig struct {
+	Path string
+	// MaxSize rotates the file once a write would take it past this many
+	// bytes; RotateEvery rotates it once it is older than the interval.
+	// Zero disables either trigger.
+	MaxSize     int64
+	RotateEvery time.Duration
+	// MaxBackups and MaxAge bound how many rotated files are kept and for
+	// how long. Zero keeps them all.
+	MaxBackups int
+	MaxAge     time.Duration
+	Compress   bool
+}
+
+// RotatingFile is an io.Writer appending to a file that it rotates by size
+// and age. Rotated files are named <name>-<timestamp><ext> next to the
+// original; compression and retention run in the background.
+type RotatingFile struct {
+	config FileConfig
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	openedAt time.Time
+	retryAt  time.Time
+	closed   bool
+
+	mill     chan struct{}
+	millDone chan struct{}
+}
+
+func OpenRotatingFile(config FileConfig) (*RotatingFile, error) {
+	if config.Path == "" {
+		return nil, fmt.Errorf("log file path must be provided")
+	}
+	if err := os.MkdirAll(filepath.Dir(config.Path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	rotatingFile := &RotatingFile{
+		config:   config,
+		mill:     make(chan struct{}, 1),
+		millDone: make(chan struct{}),
+	}
+	if err := rotatingFile.open(); err != nil {
+		return nil, err
+	}
+
+	go rotatingFile.runMill()
+	rotatingFile.triggerMill()
+
+	return rotatingFile, nil
+}
+
+func (rotatingFile *RotatingFile) open() error {
+	file, err := os.OpenFile(rotatingFile.config.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+
+	rotatingFile.file = file
+	rotatingFile.size = info.Size()
+	rotatingFile.openedAt = time.Now()
+	if info.Size() > 0 {
+		// An existing file is as old as its last rotation, which is
+		// approximated by its modification time.
+		rotatingFile.openedAt = info.ModTime()
+	}
+	return nil
+}
+
+func (rotatingFile *RotatingFile) Write(p []byte) (int, error) {
+	rotatingFile.mu.Lock()
+	defer rotatingFile.mu.Unlock()
+
+	if rotatingFile.closed {
+		return 0, os.ErrClosed
+	}
+	if rotatingFile.file == nil {
+		// A failed rotation could not reopen the file either.
+		if err := rotatingFile.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	if rotatingFile.shouldRotate(int64(len(p))) {
+		if err := rotatingFile.rotate(); err != nil {
+			// Records cannot report errors. Logging carries on in the
+			// original file and the failure is told once per attempt.
+			fmt.Fprintf(os.Stderr, "log rotation: %v\n", err)
+			if rotatingFile.file == nil {
+				return 0, err
+			}
+		}
+	}
+
+	n, err := rotatingFile.file.Write(p)
+	rotatingFile.size += int64(n)
+	return n, err
+}
+
+func (rotatingFile *RotatingFile) shouldRotate(incoming int64) bool {
+	if rotatingFile.size == 0 || time.Now().Before(rotatingFile.retryAt) {
+		return false
+	}
+	if rotatingFile.config.MaxSize > 0 && rotatingFile.size+incoming > rotatingFile.config.MaxSize {
+		return true
+	}
+	return rotatingFile.config.RotateEvery > 0 && time.Since(rotatingFile.openedAt) >= rotatingFile.config.RotateEvery
+}
+
+// rotate moves the file aside and starts a new one. When that fails, the
+// file at Path is reopened for appending and rotation is not attempted
+// again for rotationRetryInterval.
+func (rotatingFile *RotatingFile) rotate() error {
+	err := rotatingFile.file.Close()
+	rotatingFile.file = nil
+	if err != nil {
+		err = fmt.Errorf("failed to close log file: %w", err)
+	} else if err = renameFile(rotatingFile.config.Path, rotatingFile.backupName(time.Now())); err != nil {
+		err = fmt.Errorf("failed to rotate log file: %w", err)
+	} else if err = rotatingFile.open(); err == nil {
+		rotatingFile.triggerMill()
+		return nil
+	}
+
+	rotatingFile.retryAt = time.Now().Add(rotationRetryInterval)
+	if rotatingFile.file == nil {
+		if reopenErr := rotatingFile.open(); reopenErr != nil {
+			return errors.Join(err, reopenErr)
+		}
+	}
+	return err
+}
+
+func (rotatingFile *RotatingFile) backupName(t time.Time) string {
+	dir, prefix, ext := rotatingFile.nameParts()
+	return filepath.Join(dir, prefix+t.UTC().Format(backupTimeLayout)+ext)
+}
+
+func (rotatingFile *RotatingFile) nameParts() (dir string, prefix string, ext string) {
+	dir = filepath.Dir(rotatingFile.config.Path)
+	base := filepath.Base(rotatingFile.config.Path)
+	ext = filepath.Ext(base)
+	return dir, strings.TrimSuffix(base, ext) + "-", ext
+}
+
+func (rotatingFile *RotatingFile) Sync() error {
+	rotatingFile.mu.Lock()
+	defer rotatingFile.mu.Unlock()
+
+	if rotatingFile.file == nil {
+		return nil
+	}
+	return rotatingFile.file.Sync()
+}
+
+// Close closes the file and waits for pending compression and cleanup.
+func (rotatingFile *RotatingFile) Close() error {
+	rotatingFile.mu.Lock()
+	if rotatingFile.closed {
+		rotatingFile.mu.Unlock()
+		return nil
+	}
+	rotatingFile.closed = true
+	var err error
+	if rotatingFile.file != nil {
+		err = rotatingFile.file.Close()
+		rotatingFile.file = nil
+	}
+	rotatingFile.mu.Unlock()
+
+	close(rotatingFile.mill)
+	<-rotatingFile.millDone
+	return err
+}
+
+func (rotatingFile *RotatingFile) triggerMill() {
+	select {
+	case rotatingFile.mill <- struct{}{}:
+	default:
+	}
+}
+
+func (rotatingFile *RotatingFile) runMill() {
+	defer close(rotatingFile.millDone)
+	for range rotatingFile.mill {
+		if err := rotatingFile.millOnce(); err != nil {
+			fmt.Fprintf(os.Stderr, "log rotation: %v\n", err)
+		}
+	}
+}
+
+type backupFile struct {
+	path      string
+	rotatedAt time.Time
+}
+
+// millOnce compresses uncompressed backups and removes those past the
+// retention limits.
+func (rotatingFile *RotatingFile) millOnce() error {
+	backups, err := rotatingFile.backups()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	var keep []backupFile
+	for i, backup := range backups {
+		expired := rotatingFile.config.MaxAge > 0 && time.Since(backup.rotatedAt) > rotatingFile.config.MaxAge
+		excess := rotatingFile.config.MaxBackups > 0 && i >= rotatingFile.config.MaxBackups
+		if expired || excess {
+			if err := os.Remove(backup.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		keep = append(keep, backup)
+	}
+
+	if rotatingFile.config.Compress {
+		for _, backup := range keep {
+			if !strings.HasSuffix(backup.path, ".gz") {
+				errs = append(errs, compressFile(backup.path))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// backups lists rotated files, newest first.
+func (rotatingFile *RotatingFile) backups() ([]backupFile, error) {
+	dir, prefix, ext := rotatingFile.nameParts()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list log directory: %w", err)
+	}
+
+	var backups []backupFile
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimSuffix(strings.TrimPrefix(name, prefix), ".gz"), ext)
+		rotatedAt, err := time.Parse(backupTimeLayout, stamp)
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backupFile{path: filepath.Join(dir, name), rotatedAt: rotatedAt})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].rotatedAt.After(backups[j].rotatedAt)
+	})
+	return backups, nil
+}
+
+func compressFile(path string) error {
+	source, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	target, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+
+	gzipWriter := gzip.NewWriter(target)
+	if _, err := io.Copy(gzipWriter, source); err != nil {
+		target.Close()
+		os.Remove(path + ".gz")
+		return fmt.Errorf("failed to compress %s: %w", path, err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		target.Close()
+		os.Remove(path + ".gz")
+		return fmt.Errorf("failed to compress %s: %w", path, err)
+	}
+	if err := target.Close(); err != nil {
+		return err
+	}
+
+	return os.Remove(path)
+}
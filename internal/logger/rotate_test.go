@@ -0,0 +1,136 @@
+package logger
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotatingFileRotatesBySize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.log")
+	file, err := OpenRotatingFile(FileConfig{Path: path, MaxSize: 10})
+	if err != nil {
+		t.Fatalf("OpenRotatingFile: %v", err)
+	}
+
+	for _, line := range []string{"first\n", "second\n", "third\n"} {
+		if _, err := file.Write([]byte(line)); err != nil {
+			t.Fatalf("Write(%q): %v", line, err)
+		}
+		// Backups are named to the millisecond.
+		time.Sleep(2 * time.Millisecond)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	if body, _ := os.ReadFile(path); string(body) != "third\n" {
+		t.Errorf("current file = %q, want the last line only", body)
+	}
+	backups, err := file.backups()
+	if err != nil || len(backups) != 2 {
+		t.Fatalf("backups = %v, %v, want 2", backups, err)
+	}
+	if body, _ := os.ReadFile(backups[0].path); string(body) != "second\n" {
+		t.Errorf("newest backup = %q, want the second line", body)
+	}
+
+	if _, err := file.Write([]byte("late\n")); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Write after Close = %v, want os.ErrClosed", err)
+	}
+}
+
+func TestRotatingFileKeepsLoggingWhenRotationFails(t *testing.T) {
+	renames := 0
+	renameFile = func(string, string) error {
+		renames++
+		return errors.New("rename refused")
+	}
+	t.Cleanup(func() { renameFile = os.Rename })
+
+	path := filepath.Join(t.TempDir(), "api.log")
+	file, err := OpenRotatingFile(FileConfig{Path: path, MaxSize: 10})
+	if err != nil {
+		t.Fatalf("OpenRotatingFile: %v", err)
+	}
+	defer file.Close()
+
+	for _, line := range []string{"first\n", "second\n", "third\n"} {
+		if n, err := file.Write([]byte(line)); n != len(line) || err != nil {
+			t.Fatalf("Write(%q) = %d, %v, want it written", line, n, err)
+		}
+	}
+
+	if body, _ := os.ReadFile(path); string(body) != "first\nsecond\nthird\n" {
+		t.Errorf("file = %q, want every line appended", body)
+	}
+	if renames != 1 {
+		t.Errorf("rotation attempted %d times, want 1 within the retry interval", renames)
+	}
+}
+
+func TestRotatingFileRetention(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name    string
+		config  FileConfig
+		want    []string
+		backups []time.Duration
+	}{
+		{
+			name:    "max backups",
+			config:  FileConfig{MaxBackups: 2},
+			backups: []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute},
+			want:    []string{"api-1.log", "api-2.log"},
+		},
+		{
+			name:    "max age",
+			config:  FileConfig{MaxAge: time.Hour},
+			backups: []time.Duration{time.Minute, 2 * time.Hour},
+			want:    []string{"api-1.log"},
+		},
+		{
+			name:    "compress",
+			config:  FileConfig{Compress: true},
+			backups: []time.Duration{time.Minute},
+			want:    []string{"api-1.log.gz"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			test.config.Path = filepath.Join(dir, "api.log")
+			file := &RotatingFile{config: test.config}
+
+			// Backups are numbered by age so the expectations stay readable.
+			names := make(map[string]string)
+			for i, age := range test.backups {
+				path := file.backupName(now.Add(-age))
+				if err := os.WriteFile(path, []byte("line\n"), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				names[filepath.Base(path)] = "api-" + string(rune('1'+i)) + ".log"
+			}
+
+			if err := file.millOnce(); err != nil {
+				t.Fatalf("millOnce: %v", err)
+			}
+
+			entries, _ := os.ReadDir(dir)
+			var got []string
+			for _, entry := range entries {
+				name := entry.Name()
+				base := strings.TrimSuffix(name, ".gz")
+				got = append(got, names[base]+strings.TrimPrefix(name, base))
+			}
+			sort.Strings(got)
+			if strings.Join(got, ",") != strings.Join(test.want, ",") {
+				t.Errorf("files = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package metrics
+
+import (
+	"database/sql"
+	"fiber-auth-api/internal/types"
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/adaptor"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"strconv"
+	"time"
+)
+
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+
+	// unmatchedRoute labels requests that did not match any route, so
+	// scanners hitting random paths cannot blow up label cardinality.
+	unmatchedRoute = "unmatched"
+)
+
+var (
+	registry = prometheus.NewRegistry()
+
+	httpRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests processed, by method, route template and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request latency, by method and route template.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	httpRequestsInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "http_requests_in_flight",
+		Help: "HTTP requests currently being served.",
+	})
+
+	authSignupTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_signup_total",
+		Help: "Signup attempts, by outcome and failure reason.",
+	}, []string{"outcome", "reason"})
+
+	authSigninTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_signin_total",
+		Help: "Signin attempts, by outcome and failure reason.",
+	}, []string{"outcome", "reason"})
+
+	passwordHashDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "auth_password_hash_duration_seconds",
+		Help:    "Time spent in bcrypt, by operation (hash or verify).",
+		Buckets: []float64{0.01, 0.025, 0.05, 0.075, 0.1, 0.15, 0.25, 0.5, 1, 2},
+	}, []string{"operation"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequestsTotal,
+		httpRequestDuration,
+		httpRequestsInFlight,
+		authSignupTotal,
+		authSigninTotal,
+		passwordHashDuration,
+	)
+}
+
+// RegisterDBStats exports the sql.DBStats of db labelled with dbName.
+func RegisterDBStats(db *sql.DB, dbName string) error {
+	return registry.Register(collectors.NewDBStatsCollector(db, dbName))
+}
+
+// Handler serves the registry in the Prometheus text exposition format.
+func Handler() fiber.Handler {
+	return adaptor.HTTPHandler(promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+}
+
+// Middleware records request counts and latency labelled by the matched
+// route template (e.g. /api/v1/:id) rather than the raw path.
+func Middleware() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		start := time.Now()
+		middlewareRoute := c.Route()
+		httpRequestsInFlight.Inc()
+		defer httpRequestsInFlight.Dec()
+
+		err := c.Next()
+
+		// A returned error is only rendered by the ErrorHandler once the
+		// whole chain has unwound, so take its status from the error.
+		status := c.Response().StatusCode()
+		if err != nil {
+			status = types.StatusCode(err)
+		}
+
+		// c.Route() still points at this middleware when nothing matched.
+		route := c.Route().Path
+		if c.Route() == middlewareRoute {
+			route = unmatchedRoute
+		}
+
+		httpRequestsTotal.WithLabelValues(c.Method(), route, strconv.Itoa(status)).Inc()
+		httpRequestDuration.WithLabelValues(c.Method(), route).Observe(time.Since(start).Seconds())
+
+		return err
+	}
+}
+
+func RecordSignup(outcome string, reason string) {
+	authSignupTotal.WithLabelValues(outcome, reason).Inc()
+}
+
+func RecordSignin(outcome string, reason string) {
+	authSigninTotal.WithLabelValues(outcome, reason).Inc()
+}
+
+func ObservePasswordHash(operation string, duration time.Duration) {
+	passwordHashDuration.WithLabelValues(operation).Observe(duration.Seconds())
+}
@@ -0,0 +1,125 @@
+package metrics
+
+import (
+	"fiber-auth-api/internal/types"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// metricValue reads a counter, or a histogram's sample count, from the
+// registry; metrics that were never observed read as zero.
+func metricValue(t *testing.T, name string, labels map[string]string) float64 {
+	t.Helper()
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather: %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			if matchLabels(metric, labels) {
+				if histogram := metric.GetHistogram(); histogram != nil {
+					return float64(histogram.GetSampleCount())
+				}
+				return metric.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
+
+func matchLabels(metric *dto.Metric, labels map[string]string) bool {
+	if len(metric.GetLabel()) != len(labels) {
+		return false
+	}
+	for _, label := range metric.GetLabel() {
+		if labels[label.GetName()] != label.GetValue() {
+			return false
+		}
+	}
+	return true
+}
+
+func TestMiddlewareLabelsByRouteTemplate(t *testing.T) {
+	app := fiber.New()
+	app.Use(Middleware())
+	app.Get("/metrics-test/users/:id", func(c fiber.Ctx) error {
+		return c.SendString(c.Params("id"))
+	})
+	app.Get("/metrics-test/missing", func(c fiber.Ctx) error {
+		return types.ErrUserNotFound
+	})
+
+	tests := []struct {
+		name     string
+		path     string
+		route    string
+		status   string
+		requests int
+	}{
+		{"path parameters share a template", "/metrics-test/users/", "/metrics-test/users/:id", "200", 2},
+		{"returned errors use their status", "/metrics-test/missing", "/metrics-test/missing", "404", 1},
+		{"unmatched paths share one label", "/metrics-test/nope-", unmatchedRoute, "404", 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			counter := map[string]string{"method": "GET", "route": test.route, "status": test.status}
+			histogram := map[string]string{"method": "GET", "route": test.route}
+			beforeCount := metricValue(t, "http_requests_total", counter)
+			beforeObserved := metricValue(t, "http_request_duration_seconds", histogram)
+
+			for i := range test.requests {
+				path := test.path
+				if strings.HasSuffix(path, "/") || strings.HasSuffix(path, "-") {
+					path += string(rune('a' + i))
+				}
+				resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, path, nil))
+				if err != nil {
+					t.Fatalf("GET %s: %v", path, err)
+				}
+				resp.Body.Close()
+			}
+
+			if got := metricValue(t, "http_requests_total", counter) - beforeCount; got != float64(test.requests) {
+				t.Errorf("http_requests_total%v grew by %v, want %d", counter, got, test.requests)
+			}
+			if got := metricValue(t, "http_request_duration_seconds", histogram) - beforeObserved; got != float64(test.requests) {
+				t.Errorf("http_request_duration_seconds%v observed %v requests, want %d", histogram, got, test.requests)
+			}
+		})
+	}
+}
+
+func TestHandlerExposesAuthMetrics(t *testing.T) {
+	RecordSignup(OutcomeFailure, "duplicate")
+	RecordSignin(OutcomeSuccess, "")
+	ObservePasswordHash("hash", 50*time.Millisecond)
+
+	app := fiber.New()
+	app.Get("/metrics", Handler())
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/metrics", nil))
+	if err != nil {
+		t.Fatalf("GET /metrics: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	for _, want := range []string{
+		`auth_signup_total{outcome="failure",reason="duplicate"}`,
+		`auth_signin_total{outcome="success",reason=""}`,
+		`auth_password_hash_duration_seconds_count{operation="hash"}`,
+		`go_goroutines`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("/metrics does not contain %s", want)
+		}
+	}
+}
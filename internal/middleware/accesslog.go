@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"fiber-auth-api/internal/logger"
+	"github.com/gofiber/fiber/v3"
+	"time"
+)
+
+// AccessLog writes one access log entry per request. It must run after
+// RequestID so the entry carries the request id and authenticated user,
+// and after i18n so the errors it renders are localized. Middleware that
+// runs before it sees no errors, only their responses.
+func AccessLog(accessLogger *logger.AccessLogger) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		start := time.Now()
+
+		// A returned error would only be rendered once the whole chain has
+		// unwound; rendering it here, as Fiber would, lets the entry record
+		// the status and size of the response the client gets.
+		if err := c.Next(); err != nil {
+			if catch := c.App().ErrorHandler(c, err); catch != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		status := c.Response().StatusCode()
+		if !accessLogger.Sampled(status) {
+			return nil
+		}
+
+		ctx := c.UserContext()
+		accessLogger.Log(ctx, logger.AccessEntry{
+			Time:      start,
+			Method:    c.Method(),
+			URI:       c.OriginalURL(),
+			Protocol:  c.Protocol(),
+			Route:     c.Route().Path,
+			Status:    status,
+			Bytes:     len(c.Response().Body()),
+			Latency:   time.Since(start),
+			ClientIP:  c.IP(),
+			UserID:    logger.UserIDFromContext(ctx),
+			RequestID: logger.RequestIDFromContext(ctx),
+			UserAgent: c.Get(fiber.HeaderUserAgent),
+			Referer:   c.Get(fiber.HeaderReferer),
+		})
+
+		return nil
+	}
+}
@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"fiber-auth-api/internal/logger"
+	"io"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func TestAccessLogRecordsRenderedErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "access.log")
+	if err := logger.InitializeLogger(logger.SlogLogConfig{Sinks: []logger.SinkConfig{{Output: path, JSON: true}}}); err != nil {
+		t.Fatalf("InitializeLogger: %v", err)
+	}
+	t.Cleanup(func() {
+		logger.Close()
+		logger.InitializeLogger()
+	})
+	accessLogger, err := logger.NewAccessLogger(logger.AccessLogConfig{Format: logger.AccessFormatJSON, SuccessSampleRate: 1})
+	if err != nil {
+		t.Fatalf("NewAccessLogger: %v", err)
+	}
+
+	app := fiber.New(fiber.Config{
+		ErrorHandler: func(c fiber.Ctx, err error) error {
+			return c.Status(fiber.StatusTeapot).SendString("rendered: " + err.Error())
+		},
+	})
+	app.Use(AccessLog(accessLogger))
+	app.Get("/ok", func(c fiber.Ctx) error {
+		return c.SendString("hello")
+	})
+	app.Get("/fail", func(c fiber.Ctx) error {
+		return errors.New("boom")
+	})
+
+	tests := []struct {
+		path       string
+		wantStatus int
+		wantBody   string
+	}{
+		{"/ok", fiber.StatusOK, "hello"},
+		{"/fail", fiber.StatusTeapot, "rendered: boom"},
+	}
+	for _, test := range tests {
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, test.path, nil))
+		if err != nil {
+			t.Fatalf("GET %s: %v", test.path, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != test.wantStatus || string(body) != test.wantBody {
+			t.Errorf("GET %s = %d %q, want %d %q", test.path, resp.StatusCode, body, test.wantStatus, test.wantBody)
+		}
+	}
+	if err := logger.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	entries := make(map[string]map[string]any)
+	for _, line := range strings.Split(strings.TrimSpace(string(contents)), "\n") {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Unmarshal(%s): %v", line, err)
+		}
+		if uri, ok := entry["uri"].(string); ok {
+			entries[uri] = entry
+		}
+	}
+	for _, test := range tests {
+		entry := entries[test.path]
+		if entry == nil {
+			t.Errorf("no access log entry for %s", test.path)
+			continue
+		}
+		if status := int(entry["status"].(float64)); status != test.wantStatus {
+			t.Errorf("%s logged status %d, want %d", test.path, status, test.wantStatus)
+		}
+		if bytes := int(entry["bytes"].(float64)); bytes != len(test.wantBody) {
+			t.Errorf("%s logged %d bytes, want %d", test.path, bytes, len(test.wantBody))
+		}
+	}
+}
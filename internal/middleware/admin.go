@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"fiber-auth-api/internal/types"
+	"github.com/gofiber/fiber/v3"
+	"strings"
+)
+
+// AdminAuth requires the admin token as a bearer token. Both sides are
+// hashed first so the comparison takes the same time whatever the length
+// of the presented token.
+func AdminAuth(token string) fiber.Handler {
+	expected := sha256.Sum256([]byte(token))
+
+	return func(c fiber.Ctx) error {
+		presented, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+		actual := sha256.Sum256([]byte(presented))
+
+		if !ok || token == "" || subtle.ConstantTimeCompare(expected[:], actual[:]) != 1 {
+			c.Set(fiber.HeaderWWWAuthenticate, `Bearer realm="admin"`)
+			return types.ErrUnauthorized
+		}
+
+		return c.Next()
+	}
+}
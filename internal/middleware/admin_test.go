@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"fiber-auth-api/internal/types"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func TestAdminAuth(t *testing.T) {
+	const token = "0123456789abcdef0123456789abcdef"
+
+	tests := []struct {
+		name          string
+		token         string
+		authorization string
+		wantStatus    int
+	}{
+		{"matching token", token, "Bearer " + token, fiber.StatusOK},
+		{"missing header", token, "", fiber.StatusUnauthorized},
+		{"wrong token", token, "Bearer " + token[1:], fiber.StatusUnauthorized},
+		{"wrong scheme", token, "Basic " + token, fiber.StatusUnauthorized},
+		{"disabled without a token", "", "Bearer ", fiber.StatusUnauthorized},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			app := fiber.New(fiber.Config{
+				ErrorHandler: func(c fiber.Ctx, err error) error {
+					return c.SendStatus(types.StatusCode(err))
+				},
+			})
+			app.Use(AdminAuth(test.token))
+			app.Get("/admin", func(c fiber.Ctx) error {
+				return c.SendStatus(fiber.StatusOK)
+			})
+
+			req := httptest.NewRequest(fiber.MethodGet, "/admin", nil)
+			if test.authorization != "" {
+				req.Header.Set(fiber.HeaderAuthorization, test.authorization)
+			}
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("GET /admin: %v", err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != test.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, test.wantStatus)
+			}
+			challenge := resp.Header.Get(fiber.HeaderWWWAuthenticate)
+			if (challenge != "") != (test.wantStatus == fiber.StatusUnauthorized) {
+				t.Errorf("WWW-Authenticate = %q", challenge)
+			}
+		})
+	}
+}
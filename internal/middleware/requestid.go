@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"fiber-auth-api/internal/logger"
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"regexp"
+)
+
+const HeaderRequestID = "X-Request-ID"
+
+// Incoming ids are echoed into logs and headers, so only short ids made of
+// safe characters are accepted; anything else is replaced.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._\-]{1,128}$`)
+
+// RequestID accepts the caller's X-Request-ID or generates one, returns it on
+// the response and attaches it to the request's user context for logging.
+func RequestID() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		requestID := c.Get(HeaderRequestID)
+		if !validRequestID.MatchString(requestID) {
+			requestID = uuid.NewString()
+		}
+		c.Set(HeaderRequestID, requestID)
+
+		fields := logger.NewRequestFields(requestID)
+		fields.SetRouteFunc(func() string {
+			return c.Route().Path
+		})
+		c.SetUserContext(logger.ContextWithRequestFields(c.UserContext(), fields))
+
+		err := c.Next()
+
+		fields.SetRoute(c.Route().Path)
+		return err
+	}
+}
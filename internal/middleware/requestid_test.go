@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"fiber-auth-api/internal/logger"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+)
+
+func TestRequestID(t *testing.T) {
+	app := fiber.New()
+	app.Use(RequestID())
+	app.Get("/", func(c fiber.Ctx) error {
+		return c.SendString(logger.RequestIDFromContext(c.UserContext()))
+	})
+
+	tests := []struct {
+		name     string
+		incoming string
+		keep     bool
+	}{
+		{"generated when missing", "", false},
+		{"caller id accepted", "abc-123_DEF.4", true},
+		{"unsafe characters replaced", "abcé <script>", false},
+		{"overlong id replaced", strings.Repeat("a", 129), false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+			if test.incoming != "" {
+				req.Header.Set(HeaderRequestID, test.incoming)
+			}
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("GET /: %v", err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+
+			requestID := resp.Header.Get(HeaderRequestID)
+			if string(body) != requestID {
+				t.Errorf("context request id %q differs from the header %q", body, requestID)
+			}
+			if test.keep {
+				if requestID != test.incoming {
+					t.Errorf("request id = %q, want the caller's %q", requestID, test.incoming)
+				}
+			} else if _, err := uuid.Parse(requestID); err != nil {
+				t.Errorf("request id = %q, want a generated uuid", requestID)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	file := func(body string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(body)} }
+	tests := []struct {
+		name    string
+		files   fstest.MapFS
+		want    []string
+		wantErr string
+	}{
+		{
+			name: "pairs and orders by version",
+			files: fstest.MapFS{
+				"m/000010_add_index.up.sql":      file("up 10"),
+				"m/000002_create_users.up.sql":   file("up 2"),
+				"m/000002_create_users.down.sql": file("down 2"),
+				"m/README.md":                    file("ignored"),
+				"m/000003_Bad-Name.up.sql":       file("ignored"),
+			},
+			want: []string{"2 create_users: up 2 / down 2", "10 add_index: up 10 / "},
+		},
+		{
+			name:    "missing up file",
+			files:   fstest.MapFS{"m/000001_create_users.down.sql": file("down")},
+			wantErr: "000001_create_users has no up file",
+		},
+		{
+			name: "version used twice",
+			files: fstest.MapFS{
+				"m/000001_create_users.up.sql": file("up"),
+				"m/000001_create_posts.up.sql": file("up"),
+			},
+			wantErr: "migration version 1 used by both",
+		},
+		{
+			name:    "missing directory",
+			files:   fstest.MapFS{},
+			wantErr: "failed to read migrations",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			migrations, err := loadMigrations(test.files, "m")
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("loadMigrations = %v, want an error containing %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("loadMigrations: %v", err)
+			}
+			var got []string
+			for _, migration := range migrations {
+				got = append(got, fmt.Sprintf("%d %s: %s / %s", migration.Version, migration.Name, migration.Up, migration.Down))
+			}
+			if !slices.Equal(got, test.want) {
+				t.Errorf("loaded %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	dir := t.TempDir()
+	if _, _, err := Create(dir, "  !!  "); err == nil {
+		t.Error("Create accepted a name without letters or digits")
+	}
+
+	for i, name := range []string{"Create Users!", "add-users-index"} {
+		upPath, downPath, err := Create(dir, name)
+		if err != nil {
+			t.Fatalf("Create(%q): %v", name, err)
+		}
+		base := fmt.Sprintf("%06d_%s", i+1, strings.Trim(strings.NewReplacer(" ", "_", "-", "_", "!", "").Replace(strings.ToLower(name)), "_"))
+		if filepath.Base(upPath) != base+".up.sql" || filepath.Base(downPath) != base+".down.sql" {
+			t.Errorf("Create(%q) = %s, %s, want %s.{up,down}.sql", name, upPath, downPath, base)
+		}
+		body, err := os.ReadFile(upPath)
+		if err != nil || !strings.HasPrefix(string(body), "-- "+base) {
+			t.Errorf("up file = %q, %v", body, err)
+		}
+	}
+}
+
+// TestSqliteMigrations applies, reverts and reapplies the embedded SQLite
+// migrations, so every down file is exercised.
+func TestSqliteMigrations(t *testing.T) {
+	db, err := sql.Open("sqlite", "file:"+filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("sql.Open: %v", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	ctx := context.Background()
+	migrator, err := NewSqliteMigrator(db, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("NewSqliteMigrator: %v", err)
+	}
+	total := len(migrator.migrations)
+
+	steps := []struct {
+		name        string
+		run         func() (int, error)
+		wantRan     int
+		wantApplied int
+	}{
+		{"up", func() (int, error) { return migrator.Up(ctx) }, total, total},
+		{"up again", func() (int, error) { return migrator.Up(ctx) }, 0, total},
+		{"down one", func() (int, error) { return migrator.Down(ctx, 1) }, 1, total - 1},
+		{"down past the first", func() (int, error) { return migrator.Down(ctx, total+1) }, total - 1, 0},
+		{"down with nothing applied", func() (int, error) { return migrator.Down(ctx, 1) }, 0, 0},
+		{"up after down", func() (int, error) { return migrator.Up(ctx) }, total, total},
+	}
+	for _, step := range steps {
+		ran, err := step.run()
+		if err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		if ran != step.wantRan {
+			t.Errorf("%s ran %d migrations, want %d", step.name, ran, step.wantRan)
+		}
+
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			t.Fatalf("%s: Status: %v", step.name, err)
+		}
+		applied := 0
+		for i, status := range statuses {
+			if status.Applied != (i < step.wantApplied) || status.Applied != (status.AppliedAt != nil) {
+				t.Errorf("%s: status of %d = %+v", step.name, status.Version, status)
+			}
+			if status.Applied {
+				applied++
+			}
+		}
+		pending, err := migrator.Pending(ctx)
+		if err != nil || applied != step.wantApplied || len(pending) != total-step.wantApplied {
+			t.Errorf("%s: %d applied and %d pending (%v), want %d applied", step.name, applied, len(pending), err, step.wantApplied)
+		}
+	}
+
+	if _, err := migrator.Down(ctx, 0); err == nil {
+		t.Error("Down(0) succeeded")
+	}
+}
@@ -0,0 +1,31 @@
+CREATE EXTENSION IF NOT EXISTS pgcrypto;
+
+CREATE TABLE IF NOT EXISTS users (
+    user_id           UUID         PRIMARY KEY DEFAULT gen_random_uuid(),
+    username          VARCHAR(50)  NOT NULL,
+    email             VARCHAR(255) NOT NULL,
+    password_hash     TEXT         NOT NULL,
+    first_name        VARCHAR(100) NOT NULL,
+    last_name         VARCHAR(100) NOT NULL,
+    is_active         BOOLEAN      NOT NULL DEFAULT TRUE,
+    is_email_verified BOOLEAN      NOT NULL DEFAULT FALSE,
+    last_login_at     TIMESTAMPTZ,
+    created_at        TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at        TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    deleted_at        TIMESTAMPTZ,
+    CONSTRAINT users_email_key UNIQUE (email),
+    CONSTRAINT users_username_key UNIQUE (username)
+);
+
+CREATE INDEX IF NOT EXISTS users_created_at_idx ON users (created_at DESC, user_id DESC);
+
+CREATE OR REPLACE FUNCTION set_updated_at() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at = NOW();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER users_set_updated_at
+    BEFORE UPDATE ON users
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
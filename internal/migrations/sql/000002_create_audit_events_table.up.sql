@@ -0,0 +1,37 @@
+-- Append-only record of security relevant events. Each row carries the hash
+-- of the previous row, so edits made with the trigger disabled are still
+-- detected by verifying the chain.
+CREATE TABLE IF NOT EXISTS audit_events (
+    event_id     BIGSERIAL    PRIMARY KEY,
+    occurred_at  TIMESTAMPTZ  NOT NULL,
+    event_type   VARCHAR(64)  NOT NULL,
+    outcome      VARCHAR(16)  NOT NULL,
+    actor_id     TEXT         NOT NULL DEFAULT '',
+    target_id    TEXT         NOT NULL DEFAULT '',
+    ip_address   TEXT         NOT NULL DEFAULT '',
+    user_agent   TEXT         NOT NULL DEFAULT '',
+    request_id   TEXT         NOT NULL DEFAULT '',
+    metadata     JSONB        NOT NULL DEFAULT '{}',
+    prev_hash    CHAR(64)     NOT NULL,
+    hash         CHAR(64)     NOT NULL,
+    CONSTRAINT audit_events_hash_key UNIQUE (hash)
+);
+
+CREATE INDEX IF NOT EXISTS audit_events_occurred_at_idx ON audit_events (occurred_at DESC, event_id DESC);
+CREATE INDEX IF NOT EXISTS audit_events_event_type_idx ON audit_events (event_type, occurred_at DESC);
+CREATE INDEX IF NOT EXISTS audit_events_actor_id_idx ON audit_events (actor_id, occurred_at DESC);
+CREATE INDEX IF NOT EXISTS audit_events_target_id_idx ON audit_events (target_id, occurred_at DESC);
+
+CREATE OR REPLACE FUNCTION audit_events_reject_change() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_events_no_update_delete
+    BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW EXECUTE FUNCTION audit_events_reject_change();
+
+CREATE TRIGGER audit_events_no_truncate
+    BEFORE TRUNCATE ON audit_events
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_events_reject_change();
@@ -0,0 +1,7 @@
+-- Emails and usernames are normalized before they are stored, but rows
+-- written earlier may still differ from a newer row only in case. These
+-- indexes reject such duplicates and serve the lower(email) lookups; the
+-- migration fails if the table already holds any, which must then be
+-- merged by hand.
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_lower_key ON users (lower(email));
+CREATE UNIQUE INDEX IF NOT EXISTS users_username_lower_key ON users (lower(username));
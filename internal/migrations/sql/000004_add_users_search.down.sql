@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS users_email_prefix_idx;
+DROP INDEX IF EXISTS users_username_prefix_idx;
+DROP INDEX IF EXISTS users_full_name_trgm_idx;
+DROP INDEX IF EXISTS users_email_trgm_idx;
+DROP INDEX IF EXISTS users_username_trgm_idx;
+DROP INDEX IF EXISTS users_search_vector_idx;
+ALTER TABLE users DROP COLUMN IF EXISTS search_vector;
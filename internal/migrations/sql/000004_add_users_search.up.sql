@@ -0,0 +1,22 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- The simple configuration keeps names and usernames as written instead of
+-- stemming them as English words. Usernames weigh most, then names, then
+-- emails.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', username), 'A') ||
+        setweight(to_tsvector('simple', first_name || ' ' || last_name), 'B') ||
+        setweight(to_tsvector('simple', email), 'C')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS users_search_vector_idx ON users USING GIN (search_vector);
+
+-- Trigram indexes serve the fuzzy % and <% matches of SearchUsers.
+CREATE INDEX IF NOT EXISTS users_username_trgm_idx ON users USING GIN (lower(username) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS users_email_trgm_idx ON users USING GIN (lower(email) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS users_full_name_trgm_idx ON users USING GIN (lower(first_name || ' ' || last_name) gin_trgm_ops);
+
+-- Pattern indexes serve the LIKE 'prefix%' lookups of AutocompleteUsers.
+CREATE INDEX IF NOT EXISTS users_username_prefix_idx ON users (lower(username) text_pattern_ops);
+CREATE INDEX IF NOT EXISTS users_email_prefix_idx ON users (lower(email) text_pattern_ops);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS users_username_skeleton_key;
+ALTER TABLE users DROP COLUMN IF EXISTS username_skeleton;
@@ -0,0 +1,18 @@
+-- username_skeleton is the username with every character that renders like
+-- a Latin letter replaced by that letter, as validation.UsernameSkeleton
+-- computes it; the unique index keeps a second account from imitating an
+-- existing one, as "pаypal" with a Cyrillic а would "paypal". Existing rows
+-- are backfilled with the same mapping, and the migration fails if two of
+-- them already look alike, which must then be resolved by hand. Changing
+-- the mapping takes a migration recomputing the column.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS username_skeleton TEXT;
+
+UPDATE users SET username_skeleton = translate(
+    lower(username),
+    'авеһіјкӏмнорԛѕтуԝхԁɡүαβεηικνορτυχω01ıℓ',
+    'abehijklmhopqstywxdgyabenikvoptuxwolil'
+);
+
+ALTER TABLE users ALTER COLUMN username_skeleton SET NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_username_skeleton_key ON users (username_skeleton);
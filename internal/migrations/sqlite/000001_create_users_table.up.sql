@@ -0,0 +1,37 @@
+-- SQLite counterpart of the Postgres users table. Timestamps are UTC text
+-- with six fractional digits, which sorts in time order; booleans are 0 and
+-- 1. id is the stable rowid the search index refers to, user_id the public
+-- key, a random UUID as in Postgres.
+CREATE TABLE IF NOT EXISTS users (
+    id                INTEGER   PRIMARY KEY,
+    user_id           TEXT      NOT NULL UNIQUE DEFAULT (lower(
+                          hex(randomblob(4)) || '-' || hex(randomblob(2)) || '-4' ||
+                          substr(hex(randomblob(2)), 2) || '-' ||
+                          substr('89ab', 1 + abs(random()) % 4, 1) ||
+                          substr(hex(randomblob(2)), 2) || '-' || hex(randomblob(6)))),
+    username          TEXT      NOT NULL,
+    email             TEXT      NOT NULL,
+    password_hash     TEXT      NOT NULL,
+    first_name        TEXT      NOT NULL,
+    last_name         TEXT      NOT NULL,
+    is_active         BOOLEAN   NOT NULL DEFAULT TRUE,
+    is_email_verified BOOLEAN   NOT NULL DEFAULT FALSE,
+    last_login_at     TIMESTAMP,
+    created_at        TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%f000Z', 'now')),
+    updated_at        TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%f000Z', 'now')),
+    deleted_at        TIMESTAMP
+);
+
+-- lower() only folds ASCII letters in SQLite; usernames are stored case
+-- folded and email domains lowercased, so only non-ASCII local parts
+-- compare case-sensitively.
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_lower_key ON users (lower(email));
+CREATE UNIQUE INDEX IF NOT EXISTS users_username_lower_key ON users (lower(username));
+CREATE INDEX IF NOT EXISTS users_created_at_idx ON users (created_at DESC, user_id DESC);
+
+CREATE TRIGGER IF NOT EXISTS users_set_updated_at
+    AFTER UPDATE ON users
+    FOR EACH ROW WHEN NEW.updated_at = OLD.updated_at
+BEGIN
+    UPDATE users SET updated_at = strftime('%Y-%m-%dT%H:%M:%f000Z', 'now') WHERE id = NEW.id;
+END;
@@ -0,0 +1,32 @@
+-- Full-text index for SearchUsers, kept in step with users by triggers.
+-- unicode61 folds case but, like the Postgres simple configuration, keeps
+-- diacritics.
+CREATE VIRTUAL TABLE IF NOT EXISTS users_search USING fts5(
+    username, first_name, last_name, email,
+    content = 'users', content_rowid = 'id',
+    tokenize = 'unicode61 remove_diacritics 0'
+);
+
+INSERT INTO users_search (rowid, username, first_name, last_name, email)
+    SELECT id, username, first_name, last_name, email FROM users;
+
+CREATE TRIGGER IF NOT EXISTS users_search_insert AFTER INSERT ON users
+BEGIN
+    INSERT INTO users_search (rowid, username, first_name, last_name, email)
+        VALUES (NEW.id, NEW.username, NEW.first_name, NEW.last_name, NEW.email);
+END;
+
+CREATE TRIGGER IF NOT EXISTS users_search_delete AFTER DELETE ON users
+BEGIN
+    INSERT INTO users_search (users_search, rowid, username, first_name, last_name, email)
+        VALUES ('delete', OLD.id, OLD.username, OLD.first_name, OLD.last_name, OLD.email);
+END;
+
+CREATE TRIGGER IF NOT EXISTS users_search_update
+    AFTER UPDATE OF username, first_name, last_name, email ON users
+BEGIN
+    INSERT INTO users_search (users_search, rowid, username, first_name, last_name, email)
+        VALUES ('delete', OLD.id, OLD.username, OLD.first_name, OLD.last_name, OLD.email);
+    INSERT INTO users_search (rowid, username, first_name, last_name, email)
+        VALUES (NEW.id, NEW.username, NEW.first_name, NEW.last_name, NEW.email);
+END;
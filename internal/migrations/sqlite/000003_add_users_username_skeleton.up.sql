@@ -0,0 +1,48 @@
+-- SQLite counterpart of the Postgres username_skeleton column. SQLite has
+-- no translate(), so the backfill replaces the confusable characters one
+-- at a time; the column stays nullable since SQLite cannot add NOT NULL
+-- to an existing column, and CreateUser always sets it.
+ALTER TABLE users ADD COLUMN username_skeleton TEXT;
+
+UPDATE users SET username_skeleton =
+    replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(lower(username)
+        , 'а', 'a')
+        , 'в', 'b')
+        , 'е', 'e')
+        , 'һ', 'h')
+        , 'і', 'i')
+        , 'ј', 'j')
+        , 'к', 'k')
+        , 'ӏ', 'l')
+        , 'м', 'm')
+        , 'н', 'h')
+        , 'о', 'o')
+        , 'р', 'p')
+        , 'ԛ', 'q')
+        , 'ѕ', 's')
+        , 'т', 't')
+        , 'у', 'y')
+        , 'ԝ', 'w')
+        , 'х', 'x')
+        , 'ԁ', 'd')
+        , 'ɡ', 'g')
+        , 'ү', 'y')
+        , 'α', 'a')
+        , 'β', 'b')
+        , 'ε', 'e')
+        , 'η', 'n')
+        , 'ι', 'i')
+        , 'κ', 'k')
+        , 'ν', 'v')
+        , 'ο', 'o')
+        , 'ρ', 'p')
+        , 'τ', 't')
+        , 'υ', 'u')
+        , 'χ', 'x')
+        , 'ω', 'w')
+        , '0', 'o')
+        , '1', 'l')
+        , 'ı', 'i')
+        , 'ℓ', 'l');
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_username_skeleton_key ON users (username_skeleton);
@@ -2,6 +2,8 @@ package models
 
 import (
 	"database/sql"
+	"fiber-auth-api/internal/config"
+	"fiber-auth-api/internal/lifecycle"
 	"fiber-auth-api/internal/repositories"
 	"github.com/gofiber/fiber/v3"
 	"log/slog"
@@ -11,25 +13,32 @@ type Application struct {
 	FiberApp   *fiber.App
 	SlogLogger *slog.Logger
 	PsqlDb     *sql.DB
+	// SqliteDb is set instead of PsqlDb with the sqlite database driver.
+	SqliteDb  *sql.DB
+	Config    *config.Config
+	Lifecycle *lifecycle.Lifecycle
 }
 
 func (app *Application) NewApplication(fiber *fiber.App, slogLogger *slog.Logger,
-	psqlDb *sql.DB) *Application {
+	psqlDb *sql.DB, config *config.Config, lifecycle *lifecycle.Lifecycle) *Application {
 	return &Application{
 		FiberApp:   fiber,
 		SlogLogger: slogLogger,
 		PsqlDb:     psqlDb,
+		Config:     config,
+		Lifecycle:  lifecycle,
 	}
 }
 
 type DbModel struct {
-	UserDbModel *repositories.UserRepository
+	UserDbModel repositories.UserStore
+	Repos       repositories.Repos
 }
 
-func NewDbModel(userRepository *repositories.UserRepository) *DbModel {
-	return &DbModel{UserDbModel: userRepository}
+func NewDbModel(repos repositories.Repos) *DbModel {
+	return &DbModel{UserDbModel: repos.Users, Repos: repos}
 }
 
-func (dbModel DbModel) GetUserRepository() *repositories.UserRepository {
+func (dbModel DbModel) GetUserRepository() repositories.UserStore {
 	return dbModel.UserDbModel
-}
\ No newline at end of file
+}
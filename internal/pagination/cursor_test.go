@@ -0,0 +1,73 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	codec := NewCursorCodec("secret")
+	tests := []Cursor{
+		{CreatedAt: time.Date(2024, 5, 1, 12, 0, 0, 123456000, time.UTC), Id: "a"},
+		{CreatedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.FixedZone("UTC+2", 2*60*60)), Id: "b", Backward: true},
+		{CreatedAt: time.Date(1999, 12, 31, 23, 59, 59, 1, time.UTC), Id: "c", Desc: true},
+		{CreatedAt: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), Id: "d", Backward: true, Desc: true},
+	}
+	for _, cursor := range tests {
+		token := codec.Encode(cursor)
+		if strings.ContainsAny(token, "+/=") {
+			t.Errorf("Encode(%+v) = %q, not URL safe", cursor, token)
+		}
+		decoded, err := codec.Decode(token)
+		if err != nil {
+			t.Errorf("Decode(Encode(%+v)): %v", cursor, err)
+			continue
+		}
+		if !decoded.CreatedAt.Equal(cursor.CreatedAt) || decoded.Id != cursor.Id ||
+			decoded.Backward != cursor.Backward || decoded.Desc != cursor.Desc {
+			t.Errorf("Decode(Encode(%+v)) = %+v", cursor, decoded)
+		}
+	}
+}
+
+func TestCursorRejectsTampering(t *testing.T) {
+	codec := NewCursorCodec("secret")
+	token := codec.Encode(Cursor{CreatedAt: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), Id: "a"})
+	encodedPayload, encodedSignature, _ := strings.Cut(token, ".")
+	signature, _ := base64.RawURLEncoding.DecodeString(encodedSignature)
+
+	// reencode signs payload with the real key, as only the server can.
+	reencode := func(payload string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." +
+			base64.RawURLEncoding.EncodeToString(codec.sign([]byte(payload)))
+	}
+	flipped := append([]byte(nil), signature...)
+	flipped[0] ^= 1
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"empty", ""},
+		{"no signature", encodedPayload},
+		{"payload not base64", "!!!." + encodedSignature},
+		{"signature not base64", encodedPayload + ".!!!"},
+		{"changed payload", base64.RawURLEncoding.EncodeToString([]byte(`{"t":"2024-05-01T00:00:00Z","id":"b"}`)) + "." + encodedSignature},
+		{"changed signature", encodedPayload + "." + base64.RawURLEncoding.EncodeToString(flipped)},
+		{"truncated signature", encodedPayload + "." + base64.RawURLEncoding.EncodeToString(signature[:16])},
+		{"signed with another secret", NewCursorCodec("other").Encode(Cursor{CreatedAt: time.Now(), Id: "a"})},
+		{"signed payload not json", reencode("not json")},
+		{"signed payload without id", reencode(`{"t":"2024-05-01T00:00:00Z"}`)},
+		{"signed payload with a bad time", reencode(`{"t":"yesterday","id":"a"}`)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := codec.Decode(test.token); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("Decode = %v, want ErrInvalidCursor", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,353 @@
+package repositories
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fiber-auth-api/internal/types"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// auditChainLockKey serialises appends so every event links to the one
+// before it. There is one chain, so appends from every instance take turns:
+// audited writes are limited to one commit at a time, which
+// BenchmarkAuditAppendEvent measures. Within WithAuditedTx the lock is held
+// until the enclosing transaction ends, so audited transactions must stay
+// short.
+const auditChainLockKey int64 = 7_364_219_004
+
+// GenesisAuditHash is the prev_hash of the first event in the chain.
+var GenesisAuditHash = strings.Repeat("0", 64)
+
+type AuditRepository struct {
+	DB     DBTX
+	log    *slog.Logger
+	config AuditRepositoryConfig
+}
+
+type AuditRepositoryConfig struct {
+	QueryTimeout time.Duration
+}
+
+func NewAuditRepository(db DBTX, log *slog.Logger, config AuditRepositoryConfig) *AuditRepository {
+	return &AuditRepository{
+		DB:     db,
+		log:    log,
+		config: config,
+	}
+}
+
+type AuditEventModel struct {
+	EventId    int64             `json:"event_id"`
+	OccurredAt time.Time         `json:"occurred_at"`
+	EventType  string            `json:"event_type"`
+	Outcome    string            `json:"outcome"`
+	ActorId    string            `json:"actor_id"`
+	TargetId   string            `json:"target_id"`
+	IPAddress  string            `json:"ip_address"`
+	UserAgent  string            `json:"user_agent"`
+	RequestId  string            `json:"request_id"`
+	Metadata   map[string]string `json:"metadata"`
+	PrevHash   string            `json:"prev_hash"`
+	Hash       string            `json:"hash"`
+}
+
+// AuditEventFilter narrows ListEvents; zero fields match everything. From is
+// inclusive and To exclusive.
+type AuditEventFilter struct {
+	EventType string
+	Outcome   string
+	ActorId   string
+	TargetId  string
+	From      time.Time
+	To        time.Time
+	Page      int
+	PerPage   int
+}
+
+// Offset is the number of events before Page, clamped like
+// UserListFilter.Offset.
+func (filter AuditEventFilter) Offset() int {
+	return pageOffset(filter.Page, filter.PerPage)
+}
+
+type AuditChainVerification struct {
+	Valid         bool   `json:"valid"`
+	CheckedEvents int    `json:"checked_events"`
+	HeadHash      string `json:"head_hash"`
+	// BrokenAt is the first event whose hash or link does not match.
+	BrokenAt int64  `json:"broken_at,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// auditEventHash hashes the previous hash together with every stored field
+// except the database assigned id. Metadata is marshalled from a map, so
+// its keys are always in sorted order.
+func auditEventHash(event *AuditEventModel) (string, error) {
+	metadata, err := json.Marshal(event.Metadata)
+	if err != nil {
+		return "", err
+	}
+
+	fields := []string{
+		event.PrevHash,
+		event.OccurredAt.UTC().Format(time.RFC3339Nano),
+		event.EventType,
+		event.Outcome,
+		event.ActorId,
+		event.TargetId,
+		event.IPAddress,
+		event.UserAgent,
+		event.RequestId,
+		string(metadata),
+	}
+	canonical, err := json.Marshal(fields)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// AppendEvent links event to the end of the chain and stores it, filling in
+// OccurredAt, PrevHash, Hash and EventId.
+func (auditRepo AuditRepository) AppendEvent(ctx context.Context, event *AuditEventModel) (err error) {
+	ctx, span := startQuerySpan(ctx, postgresDialect.system, "AuditRepository", "AppendEvent")
+	defer func() { endQuerySpan(span, 1, err) }()
+
+	ctx, cancel := context.WithTimeout(ctx, auditRepo.config.QueryTimeout)
+	defer cancel()
+
+	if event.Metadata == nil {
+		event.Metadata = map[string]string{}
+	}
+	// Postgres keeps microseconds; truncating first keeps the hash stable
+	// across the round trip.
+	event.OccurredAt = time.Now().UTC().Truncate(time.Microsecond)
+
+	// Within WithAuditedTx this is a savepoint, and the chain stays locked
+	// until the enclosing transaction ends.
+	nested, err := beginNested(ctx, auditRepo.DB, postgresDialect.auditedTxOptions)
+	if err != nil {
+		return auditRepo.apiError(fmt.Errorf("failed to begin audit transaction: %w", err))
+	}
+	defer nested.rollback(ctx)
+	tx := nested.tx
+
+	// The head must be read with a snapshot taken after the lock is held,
+	// which only read committed transactions take per statement.
+	if nested.savepoint != "" {
+		var isolation string
+		if err = tx.QueryRowContext(ctx, `SELECT current_setting('transaction_isolation')`).Scan(&isolation); err != nil {
+			return auditRepo.apiError(fmt.Errorf("failed to read transaction isolation: %w", err))
+		}
+		if isolation != "read committed" {
+			return fmt.Errorf("cannot append audit events in a %s transaction; use WithAuditedTx", isolation)
+		}
+	}
+
+	if _, err = tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, auditChainLockKey); err != nil {
+		return auditRepo.apiError(fmt.Errorf("failed to lock audit chain: %w", err))
+	}
+
+	err = tx.QueryRowContext(ctx, `SELECT hash FROM audit_events ORDER BY event_id DESC LIMIT 1`).Scan(&event.PrevHash)
+	if errors.Is(err, sql.ErrNoRows) {
+		event.PrevHash = GenesisAuditHash
+	} else if err != nil {
+		return auditRepo.apiError(fmt.Errorf("failed to read audit chain head: %w", err))
+	}
+
+	if event.Hash, err = auditEventHash(event); err != nil {
+		return fmt.Errorf("failed to hash audit event: %w", err)
+	}
+	metadata, err := json.Marshal(event.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit metadata: %w", err)
+	}
+
+	query := `
+		INSERT INTO audit_events (
+			occurred_at, event_type, outcome, actor_id, target_id,
+			ip_address, user_agent, request_id, metadata, prev_hash, hash
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING event_id`
+
+	err = tx.QueryRowContext(ctx, query,
+		event.OccurredAt,
+		event.EventType,
+		event.Outcome,
+		event.ActorId,
+		event.TargetId,
+		event.IPAddress,
+		event.UserAgent,
+		event.RequestId,
+		metadata,
+		event.PrevHash,
+		event.Hash,
+	).Scan(&event.EventId)
+	if err != nil {
+		return auditRepo.apiError(fmt.Errorf("failed to insert audit event: %w", err))
+	}
+
+	if err = nested.commit(ctx); err != nil {
+		return auditRepo.apiError(fmt.Errorf("failed to commit audit event: %w", err))
+	}
+	return nil
+}
+
+func (auditRepo AuditRepository) ListEvents(ctx context.Context, filter AuditEventFilter) (events []*AuditEventModel, metadata *types.Metadata, err error) {
+	ctx, span := startQuerySpan(ctx, postgresDialect.system, "AuditRepository", "ListEvents")
+	defer func() { endQuerySpan(span, len(events), err) }()
+
+	ctx, cancel := context.WithTimeout(ctx, auditRepo.config.QueryTimeout)
+	defer cancel()
+
+	var where whereClause
+	if filter.EventType != "" {
+		where.add("event_type = ?", filter.EventType)
+	}
+	if filter.Outcome != "" {
+		where.add("outcome = ?", filter.Outcome)
+	}
+	if filter.ActorId != "" {
+		where.add("actor_id = ?", filter.ActorId)
+	}
+	if filter.TargetId != "" {
+		where.add("target_id = ?", filter.TargetId)
+	}
+	if !filter.From.IsZero() {
+		where.add("occurred_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		where.add("occurred_at < ?", filter.To)
+	}
+
+	var totalRecords int
+	if err = auditRepo.DB.QueryRowContext(ctx, `SELECT count(*) FROM audit_events `+where.String(), where.args...).Scan(&totalRecords); err != nil {
+		auditRepo.log.ErrorContext(ctx, "Failed to count audit events", "error", err)
+		return nil, nil, auditRepo.apiError(err)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT event_id, occurred_at, event_type, outcome, actor_id, target_id,
+			ip_address, user_agent, request_id, metadata, prev_hash, hash
+		FROM audit_events %s
+		ORDER BY occurred_at DESC, event_id DESC
+		LIMIT %s OFFSET %s`, where.String(), where.arg(filter.PerPage), where.arg(filter.Offset()))
+
+	rows, err := auditRepo.DB.QueryContext(ctx, query, where.args...)
+	if err != nil {
+		auditRepo.log.ErrorContext(ctx, "Failed to list audit events", "error", err)
+		return nil, nil, auditRepo.apiError(err)
+	}
+	defer rows.Close()
+
+	events = make([]*AuditEventModel, 0, min(filter.PerPage, totalRecords))
+	for rows.Next() {
+		event, err := scanAuditEvent(rows)
+		if err != nil {
+			auditRepo.log.ErrorContext(ctx, "Failed to scan audit event", "error", err)
+			return nil, nil, auditRepo.apiError(err)
+		}
+		events = append(events, event)
+	}
+	if err = rows.Err(); err != nil {
+		auditRepo.log.ErrorContext(ctx, "Failed to list audit events", "error", err)
+		return nil, nil, auditRepo.apiError(err)
+	}
+
+	metadata = types.NewMetadata(totalRecords, filter.Page, filter.PerPage, len(events))
+	return events, metadata, nil
+}
+
+// VerifyChain walks the whole chain in insertion order, recomputing every
+// hash. It is not bounded by the query timeout since the table only grows.
+func (auditRepo AuditRepository) VerifyChain(ctx context.Context) (verification AuditChainVerification, err error) {
+	ctx, span := startQuerySpan(ctx, postgresDialect.system, "AuditRepository", "VerifyChain")
+	defer func() { endQuerySpan(span, verification.CheckedEvents, err) }()
+
+	rows, err := auditRepo.DB.QueryContext(ctx, `
+		SELECT event_id, occurred_at, event_type, outcome, actor_id, target_id,
+			ip_address, user_agent, request_id, metadata, prev_hash, hash
+		FROM audit_events
+		ORDER BY event_id`)
+	if err != nil {
+		return verification, auditRepo.apiError(fmt.Errorf("failed to read audit events: %w", err))
+	}
+	defer rows.Close()
+
+	verification = AuditChainVerification{Valid: true, HeadHash: GenesisAuditHash}
+	for rows.Next() {
+		event, err := scanAuditEvent(rows)
+		if err != nil {
+			return verification, auditRepo.apiError(fmt.Errorf("failed to scan audit event: %w", err))
+		}
+
+		if event.PrevHash != verification.HeadHash {
+			verification.Valid = false
+			verification.BrokenAt = event.EventId
+			verification.Reason = "prev_hash does not match the preceding event"
+			return verification, nil
+		}
+		hash, err := auditEventHash(event)
+		if err != nil {
+			return verification, fmt.Errorf("failed to hash audit event %d: %w", event.EventId, err)
+		}
+		if hash != event.Hash {
+			verification.Valid = false
+			verification.BrokenAt = event.EventId
+			verification.Reason = "event content does not match its hash"
+			return verification, nil
+		}
+
+		verification.CheckedEvents++
+		verification.HeadHash = event.Hash
+	}
+	if err = rows.Err(); err != nil {
+		return verification, auditRepo.apiError(fmt.Errorf("failed to read audit events: %w", err))
+	}
+
+	return verification, nil
+}
+
+// apiError classifies err and reports a database that could not answer as
+// types.ErrServiceUnavailable, as UserRepository does.
+func (auditRepo AuditRepository) apiError(err error) error {
+	return postgresDialect.unavailable(err)
+}
+
+func scanAuditEvent(rows *sql.Rows) (*AuditEventModel, error) {
+	var event AuditEventModel
+	var metadata []byte
+
+	err := rows.Scan(
+		&event.EventId,
+		&event.OccurredAt,
+		&event.EventType,
+		&event.Outcome,
+		&event.ActorId,
+		&event.TargetId,
+		&event.IPAddress,
+		&event.UserAgent,
+		&event.RequestId,
+		&metadata,
+		&event.PrevHash,
+		&event.Hash,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(metadata, &event.Metadata); err != nil {
+		return nil, fmt.Errorf("invalid metadata on audit event %d: %w", event.EventId, err)
+	}
+	event.OccurredAt = event.OccurredAt.UTC()
+	return &event, nil
+}
@@ -0,0 +1,166 @@
+package repositories_test
+
+import (
+	"context"
+	"fiber-auth-api/internal/repositories"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+var testAuditConfig = repositories.AuditRepositoryConfig{QueryTimeout: 30 * time.Second}
+
+// TestAuditChainUnderConcurrency appends signup events within transactions,
+// as SignUpHandler does, alongside failed signins appended on their own,
+// and checks that the chain did not fork.
+func TestAuditChainUnderConcurrency(t *testing.T) {
+	repos := repositories.NewRepos(openPostgres(t), testLogger, testUserConfig, testAuditConfig)
+	ctx := context.Background()
+
+	const signups, signins = 20, 20
+	var wg sync.WaitGroup
+	errs := make(chan error, signups+signins)
+	for i := range signups {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- repos.WithAuditedTx(ctx, func(tx repositories.Repos) error {
+				user := &repositories.UserCreateDbModel{
+					Email:        fmt.Sprintf("user%d@example.com", i),
+					Username:     fmt.Sprintf("user%d", i),
+					FirstName:    "Test",
+					LastName:     "User",
+					PasswordHash: "x",
+					IsActive:     true,
+				}
+				if err := tx.Users.CreateUser(ctx, user); err != nil {
+					return err
+				}
+				return tx.Audit.AppendEvent(ctx, &repositories.AuditEventModel{
+					EventType: "user.signup",
+					Outcome:   "success",
+					ActorId:   user.UserId,
+					TargetId:  user.UserId,
+				})
+			})
+		}()
+	}
+	for i := range signins {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- repos.Audit.AppendEvent(ctx, &repositories.AuditEventModel{
+				EventType: "auth.signin",
+				Outcome:   "failure",
+				Metadata:  map[string]string{"email": fmt.Sprintf("nobody%d@example.com", i)},
+			})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("appending: %v", err)
+		}
+	}
+
+	verification, err := repos.Audit.VerifyChain(ctx)
+	if err != nil {
+		t.Fatalf("VerifyChain: %v", err)
+	}
+	if !verification.Valid || verification.CheckedEvents != signups+signins {
+		t.Errorf("VerifyChain = %+v, want %d valid events", verification, signups+signins)
+	}
+}
+
+// TestAuditAppendRefusesSerializable checks that a WithTx transaction cannot
+// append, since its snapshot may predate the chain head.
+func TestAuditAppendRefusesSerializable(t *testing.T) {
+	repos := repositories.NewRepos(openPostgres(t), testLogger, testUserConfig, testAuditConfig)
+	ctx := context.Background()
+
+	err := repos.WithTx(ctx, func(tx repositories.Repos) error {
+		return tx.Audit.AppendEvent(ctx, &repositories.AuditEventModel{EventType: "user.signup", Outcome: "success"})
+	})
+	if err == nil {
+		t.Fatal("AppendEvent in a serializable transaction succeeded")
+	}
+	if verification, err := repos.Audit.VerifyChain(ctx); err != nil || verification.CheckedEvents != 0 {
+		t.Errorf("VerifyChain = %+v, %v, want an empty chain", verification, err)
+	}
+}
+
+// TestAuditVerifyChain edits stored events behind the append-only triggers
+// and checks that VerifyChain finds the first one changed.
+func TestAuditVerifyChain(t *testing.T) {
+	db := openPostgres(t)
+	repos := repositories.NewRepos(db, testLogger, testUserConfig, testAuditConfig)
+	ctx := context.Background()
+
+	var events []*repositories.AuditEventModel
+	for i := range 3 {
+		event := &repositories.AuditEventModel{EventType: "auth.signin", Outcome: "success", ActorId: fmt.Sprint(i)}
+		if err := repos.Audit.AppendEvent(ctx, event); err != nil {
+			t.Fatalf("AppendEvent: %v", err)
+		}
+		events = append(events, event)
+	}
+
+	verification, err := repos.Audit.VerifyChain(ctx)
+	if err != nil || !verification.Valid || verification.CheckedEvents != 3 || verification.HeadHash != events[2].Hash {
+		t.Fatalf("VerifyChain of an intact chain = %+v, %v", verification, err)
+	}
+
+	tests := []struct {
+		name   string
+		update string
+		reason string
+	}{
+		{"edited content", `UPDATE audit_events SET outcome = 'failure' WHERE event_id = $1`, "event content does not match its hash"},
+		{"relinked event", `UPDATE audit_events SET prev_hash = repeat('0', 64) WHERE event_id = $1`, "prev_hash does not match the preceding event"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tx, err := db.BeginTx(ctx, nil)
+			if err != nil {
+				t.Fatalf("BeginTx: %v", err)
+			}
+			defer tx.Rollback()
+			if _, err := tx.ExecContext(ctx, `ALTER TABLE audit_events DISABLE TRIGGER audit_events_no_update_delete`); err != nil {
+				t.Fatalf("disabling trigger: %v", err)
+			}
+			if _, err := tx.ExecContext(ctx, test.update, events[1].EventId); err != nil {
+				t.Fatalf("tampering: %v", err)
+			}
+
+			verification, err := repositories.NewAuditRepository(tx, testLogger, testAuditConfig).VerifyChain(ctx)
+			if err != nil {
+				t.Fatalf("VerifyChain: %v", err)
+			}
+			if verification.Valid || verification.BrokenAt != events[1].EventId || verification.Reason != test.reason {
+				t.Errorf("VerifyChain = %+v, want broken at %d: %s", verification, events[1].EventId, test.reason)
+			}
+		})
+	}
+}
+
+// BenchmarkAuditAppendEvent measures appends from concurrent requests, all
+// of which queue on the chain lock; run it with -cpu to see that the rate
+// does not grow with the number of writers.
+func BenchmarkAuditAppendEvent(b *testing.B) {
+	repos := repositories.NewRepos(openPostgres(b), testLogger, testUserConfig, testAuditConfig)
+	ctx := context.Background()
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			err := repos.Audit.AppendEvent(ctx, &repositories.AuditEventModel{EventType: "auth.signin", Outcome: "success"})
+			if err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+	b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "events/s")
+}
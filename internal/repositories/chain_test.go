@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAuditEventHash(t *testing.T) {
+	base := AuditEventModel{
+		EventId:    1,
+		OccurredAt: time.Date(2024, 5, 1, 12, 0, 0, 123456000, time.UTC),
+		EventType:  "user.signup",
+		Outcome:    "success",
+		ActorId:    "actor",
+		TargetId:   "target",
+		IPAddress:  "192.0.2.1",
+		UserAgent:  "curl/8",
+		RequestId:  "request",
+		Metadata:   map[string]string{"a": "1", "b": "2"},
+		PrevHash:   GenesisAuditHash,
+	}
+	baseHash, err := auditEventHash(&base)
+	if err != nil {
+		t.Fatalf("auditEventHash: %v", err)
+	}
+	if len(baseHash) != 64 {
+		t.Fatalf("auditEventHash = %q, want 64 hex digits", baseHash)
+	}
+
+	tests := []struct {
+		name   string
+		change func(event *AuditEventModel)
+		same   bool
+	}{
+		{"event id is not hashed", func(event *AuditEventModel) { event.EventId = 2 }, true},
+		{"stored hash is not hashed", func(event *AuditEventModel) { event.Hash = "x" }, true},
+		{"time zone does not matter", func(event *AuditEventModel) {
+			event.OccurredAt = event.OccurredAt.In(time.FixedZone("UTC+2", 2*60*60))
+		}, true},
+		{"metadata order does not matter", func(event *AuditEventModel) {
+			event.Metadata = map[string]string{"b": "2", "a": "1"}
+		}, true},
+		{"prev hash", func(event *AuditEventModel) { event.PrevHash = baseHash }, false},
+		{"occurred at", func(event *AuditEventModel) { event.OccurredAt = event.OccurredAt.Add(time.Microsecond) }, false},
+		{"event type", func(event *AuditEventModel) { event.EventType = "auth.signin" }, false},
+		{"outcome", func(event *AuditEventModel) { event.Outcome = "failure" }, false},
+		{"actor", func(event *AuditEventModel) { event.ActorId = "other" }, false},
+		{"target", func(event *AuditEventModel) { event.TargetId = "other" }, false},
+		{"ip address", func(event *AuditEventModel) { event.IPAddress = "192.0.2.2" }, false},
+		{"user agent", func(event *AuditEventModel) { event.UserAgent = "curl/9" }, false},
+		{"request id", func(event *AuditEventModel) { event.RequestId = "other" }, false},
+		{"metadata value", func(event *AuditEventModel) { event.Metadata = map[string]string{"a": "1", "b": "3"} }, false},
+		// Field boundaries are part of the hash, so text cannot move
+		// between fields.
+		{"text moved between fields", func(event *AuditEventModel) {
+			event.ActorId, event.TargetId = "actortar", "get"
+		}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			event := base
+			test.change(&event)
+			hash, err := auditEventHash(&event)
+			if err != nil {
+				t.Fatalf("auditEventHash: %v", err)
+			}
+			if same := hash == baseHash; same != test.same {
+				t.Errorf("hash unchanged = %v, want %v", same, test.same)
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fiber-auth-api/internal/types"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// dialect holds what UserRepository does differently per database.
+type dialect struct {
+	system attribute.KeyValue
+	// now is the SQL expression for the current time.
+	now string
+	// timeArg converts a time.Time before it is passed as a query argument.
+	timeArg func(time.Time) any
+	// classify turns driver errors into a *types.DatabaseError, leaving
+	// errors it does not recognize as they are.
+	classify func(error) error
+	// txOptions begin the transactions of WithTx, and auditedTxOptions
+	// those of WithAuditedTx.
+	txOptions        *sql.TxOptions
+	auditedTxOptions *sql.TxOptions
+}
+
+var postgresDialect = dialect{
+	system:   semconv.DBSystemPostgreSQL,
+	now:      "NOW()",
+	timeArg:  func(t time.Time) any { return t },
+	classify: classifyPostgresError,
+
+	txOptions:        &sql.TxOptions{Isolation: sql.LevelSerializable},
+	auditedTxOptions: &sql.TxOptions{Isolation: sql.LevelReadCommitted},
+}
+
+// sqliteTimeLayout is how the SQLite migrations store timestamps: UTC with
+// a fixed number of fractional digits, so text comparison orders them in
+// time. Time arguments must use it too.
+const sqliteTimeLayout = "2006-01-02T15:04:05.000000Z"
+
+// SQLite transactions are serializable already; a lock that outlasts the
+// busy timeout fails with SQLITE_BUSY, which classifies as a serialization
+// failure.
+var sqliteDialect = dialect{
+	system:   semconv.DBSystemSqlite,
+	now:      `strftime('%Y-%m-%dT%H:%M:%f000Z', 'now')`,
+	timeArg:  func(t time.Time) any { return t.UTC().Format(sqliteTimeLayout) },
+	classify: classifySqliteError,
+}
+
+// classifyPostgresError sorts a *pq.Error by its SQLSTATE.
+func classifyPostgresError(err error) error {
+	if err == nil || isClassified(err) {
+		return err
+	}
+	var pqError *pq.Error
+	if !errors.As(err, &pqError) {
+		return classifyConnectionError(err)
+	}
+
+	var kind error
+	switch code := pqError.Code; {
+	case code == "23505":
+		kind = types.ErrUniqueViolation
+	case code == "23503":
+		kind = types.ErrForeignKeyViolation
+	case code == "23502":
+		kind = types.ErrNotNullViolation
+	case code == "23514":
+		kind = types.ErrCheckViolation
+	case code == "40001" || code == "40P01":
+		kind = types.ErrSerializationFailure
+	case code == "57014":
+		kind = types.ErrQueryCanceled
+	case code == "57P01" || code == "57P02" || code == "57P03":
+		kind = types.ErrDatabaseUnavailable
+	}
+	switch pqError.Code.Class() {
+	case "22":
+		kind = types.ErrInvalidData
+	case "08", "53":
+		kind = types.ErrDatabaseUnavailable
+	}
+	if kind == nil {
+		return err
+	}
+	return &types.DatabaseError{
+		Kind:       kind,
+		Code:       string(pqError.Code),
+		Constraint: pqError.Constraint,
+		Table:      pqError.Table,
+		Column:     pqError.Column,
+		Err:        err,
+	}
+}
+
+// classifySqliteError sorts a *sqlite.Error by its extended result code.
+// SQLite reports the constraint or columns involved only in the message.
+func classifySqliteError(err error) error {
+	if err == nil || isClassified(err) {
+		return err
+	}
+	var sqliteError *sqlite.Error
+	if !errors.As(err, &sqliteError) {
+		return classifyConnectionError(err)
+	}
+
+	var kind error
+	switch code := sqliteError.Code(); {
+	case code == sqlite3.SQLITE_CONSTRAINT_UNIQUE || code == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY:
+		kind = types.ErrUniqueViolation
+	case code == sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY:
+		kind = types.ErrForeignKeyViolation
+	case code == sqlite3.SQLITE_CONSTRAINT_NOTNULL:
+		kind = types.ErrNotNullViolation
+	case code == sqlite3.SQLITE_CONSTRAINT_CHECK:
+		kind = types.ErrCheckViolation
+	case code&0xff == sqlite3.SQLITE_MISMATCH || code&0xff == sqlite3.SQLITE_TOOBIG:
+		kind = types.ErrInvalidData
+	case code&0xff == sqlite3.SQLITE_BUSY || code&0xff == sqlite3.SQLITE_LOCKED:
+		kind = types.ErrSerializationFailure
+	case code&0xff == sqlite3.SQLITE_INTERRUPT:
+		kind = types.ErrQueryCanceled
+	case code&0xff == sqlite3.SQLITE_CANTOPEN || code&0xff == sqlite3.SQLITE_IOERR || code&0xff == sqlite3.SQLITE_FULL:
+		kind = types.ErrDatabaseUnavailable
+	default:
+		return err
+	}
+
+	databaseError := &types.DatabaseError{Kind: kind, Code: strconv.Itoa(sqliteError.Code()), Err: err}
+	// Messages end like "UNIQUE constraint failed: index 'users_email_lower_key' (2067)"
+	// or "NOT NULL constraint failed: users.email (1299)". The driver's own
+	// "constraint failed: " prefix is not followed by a name, as in
+	// "constraint failed: FOREIGN KEY constraint failed (787)".
+	message := sqliteError.Error()
+	if i := strings.LastIndex(message, " constraint failed: "); i >= 0 {
+		detail := message[i+len(" constraint failed: "):]
+		if j := strings.LastIndex(detail, " ("); j >= 0 {
+			detail = detail[:j]
+		}
+		detail, _, _ = strings.Cut(detail, ", ")
+		if index, ok := strings.CutPrefix(detail, "index "); ok {
+			databaseError.Constraint = strings.Trim(index, "'")
+		} else if table, column, ok := strings.Cut(detail, "."); ok {
+			databaseError.Table, databaseError.Column = table, column
+		} else {
+			databaseError.Constraint = detail
+		}
+	}
+	return databaseError
+}
+
+// classifyConnectionError recognizes the failures that are not about the
+// statement: a database that cannot be reached and statements that ran
+// out of time. A canceled context is left alone, since the caller gave up
+// rather than the database failing.
+func classifyConnectionError(err error) error {
+	var kind error
+	var netError net.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		kind = types.ErrQueryCanceled
+	case errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) ||
+		errors.Is(err, io.ErrUnexpectedEOF) || errors.As(err, &netError):
+		kind = types.ErrDatabaseUnavailable
+	default:
+		return err
+	}
+	return &types.DatabaseError{Kind: kind, Err: err}
+}
+
+// unavailable classifies err and reports a database that could not answer,
+// in time or at all, as types.ErrServiceUnavailable. Serialization failures
+// count: they remain after WithTx gave up retrying. Queries abandoned
+// because the client went away are types.ErrRequestCanceled instead.
+func (dialect dialect) unavailable(err error) error {
+	if errors.Is(err, context.Canceled) {
+		return types.ErrRequestCanceled.WithCause(err)
+	}
+	err = dialect.classify(err)
+	var databaseError *types.DatabaseError
+	if !errors.As(err, &databaseError) {
+		return err
+	}
+	switch databaseError.Kind {
+	case types.ErrDatabaseUnavailable, types.ErrQueryCanceled, types.ErrSerializationFailure:
+		return types.ErrServiceUnavailable.WithCause(err)
+	}
+	return err
+}
+
+func isClassified(err error) bool {
+	var databaseError *types.DatabaseError
+	return errors.As(err, &databaseError)
+}
@@ -0,0 +1,173 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fiber-auth-api/internal/types"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/lib/pq"
+	_ "modernc.org/sqlite"
+)
+
+func TestClassifyPostgresError(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		wantKind       error
+		wantConstraint string
+	}{
+		{"unique", &pq.Error{Code: "23505", Constraint: "users_email_lower_key"}, types.ErrUniqueViolation, "users_email_lower_key"},
+		{"foreign key", &pq.Error{Code: "23503", Constraint: "sessions_user_id_fkey"}, types.ErrForeignKeyViolation, "sessions_user_id_fkey"},
+		{"not null", &pq.Error{Code: "23502", Column: "email"}, types.ErrNotNullViolation, ""},
+		{"check", &pq.Error{Code: "23514"}, types.ErrCheckViolation, ""},
+		{"serialization", &pq.Error{Code: "40001"}, types.ErrSerializationFailure, ""},
+		{"deadlock", &pq.Error{Code: "40P01"}, types.ErrSerializationFailure, ""},
+		{"statement timeout", &pq.Error{Code: "57014"}, types.ErrQueryCanceled, ""},
+		{"admin shutdown", &pq.Error{Code: "57P01"}, types.ErrDatabaseUnavailable, ""},
+		{"data exception class", &pq.Error{Code: "22P02"}, types.ErrInvalidData, ""},
+		{"connection exception class", &pq.Error{Code: "08006"}, types.ErrDatabaseUnavailable, ""},
+		{"insufficient resources class", &pq.Error{Code: "53300"}, types.ErrDatabaseUnavailable, ""},
+		{"wrapped", fmt.Errorf("failed to create user: %w", &pq.Error{Code: "23505"}), types.ErrUniqueViolation, ""},
+		{"deadline", context.DeadlineExceeded, types.ErrQueryCanceled, ""},
+		{"canceled", fmt.Errorf("query: %w", context.Canceled), nil, ""},
+		{"bad connection", driver.ErrBadConn, types.ErrDatabaseUnavailable, ""},
+		{"closed connection", sql.ErrConnDone, types.ErrDatabaseUnavailable, ""},
+		{"unexpected eof", io.ErrUnexpectedEOF, types.ErrDatabaseUnavailable, ""},
+		{"network", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, types.ErrDatabaseUnavailable, ""},
+		{"syntax error", &pq.Error{Code: "42601"}, nil, ""},
+		{"no rows", sql.ErrNoRows, nil, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			classified := classifyPostgresError(test.err)
+			assertClassified(t, classified, test.err, test.wantKind)
+
+			var databaseError *types.DatabaseError
+			if errors.As(classified, &databaseError) && databaseError.Constraint != test.wantConstraint {
+				t.Errorf("constraint = %q, want %q", databaseError.Constraint, test.wantConstraint)
+			}
+			if again := classifyPostgresError(classified); again != classified {
+				t.Errorf("classifying twice = %v, want %v", again, classified)
+			}
+		})
+	}
+}
+
+func TestClassifySqliteError(t *testing.T) {
+	db, err := sql.Open("sqlite", "file::memory:?_pragma=foreign_keys(1)")
+	if err != nil {
+		t.Fatalf("sql.Open: %v", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	ctx := context.Background()
+	for _, statement := range []string{
+		`CREATE TABLE parents (id INTEGER PRIMARY KEY)`,
+		`CREATE TABLE things (
+			id        INTEGER PRIMARY KEY,
+			name      TEXT NOT NULL,
+			code      TEXT,
+			size      INTEGER CHECK (size > 0),
+			parent_id INTEGER REFERENCES parents (id)
+		)`,
+		`CREATE UNIQUE INDEX things_lower_name_key ON things (lower(name))`,
+		`CREATE UNIQUE INDEX things_code_key ON things (code)`,
+		`INSERT INTO things (id, name, code) VALUES (1, 'a', 'x')`,
+	} {
+		if _, err := db.ExecContext(ctx, statement); err != nil {
+			t.Fatalf("%s: %v", statement, err)
+		}
+	}
+
+	tests := []struct {
+		name           string
+		statement      string
+		wantKind       error
+		wantConstraint string
+		wantColumn     string
+	}{
+		{"unique expression index", `INSERT INTO things (name) VALUES ('A')`, types.ErrUniqueViolation, "things_lower_name_key", ""},
+		{"unique column index", `INSERT INTO things (name, code) VALUES ('b', 'x')`, types.ErrUniqueViolation, "", "code"},
+		{"primary key", `INSERT INTO things (id, name) VALUES (1, 'b')`, types.ErrUniqueViolation, "", "id"},
+		{"not null", `INSERT INTO things (name) VALUES (NULL)`, types.ErrNotNullViolation, "", "name"},
+		{"check", `INSERT INTO things (name, size) VALUES ('b', 0)`, types.ErrCheckViolation, "size > 0", ""},
+		{"foreign key", `INSERT INTO things (name, parent_id) VALUES ('b', 42)`, types.ErrForeignKeyViolation, "", ""},
+		{"syntax error", `INSERT INTO`, nil, "", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := db.ExecContext(ctx, test.statement)
+			if err == nil {
+				t.Fatal("statement succeeded")
+			}
+			classified := classifySqliteError(err)
+			assertClassified(t, classified, err, test.wantKind)
+
+			var databaseError *types.DatabaseError
+			if errors.As(classified, &databaseError) &&
+				(databaseError.Constraint != test.wantConstraint || databaseError.Column != test.wantColumn) {
+				t.Errorf("constraint, column = %q, %q, want %q, %q", databaseError.Constraint, databaseError.Column, test.wantConstraint, test.wantColumn)
+			}
+		})
+	}
+}
+
+// assertClassified checks that classified has kind and keeps err
+// reachable, or is err itself when kind is nil.
+func assertClassified(t *testing.T, classified error, err error, kind error) {
+	t.Helper()
+	if kind == nil {
+		if classified != err {
+			t.Errorf("classified %v as %v, want it left alone", err, classified)
+		}
+		return
+	}
+	if !errors.Is(classified, kind) {
+		t.Errorf("classified %v as %v, want %v", err, classified, kind)
+	}
+	if !errors.Is(classified, err) {
+		t.Errorf("classified error %v does not wrap %v", classified, err)
+	}
+}
+
+func TestUserRepositoryAPIError(t *testing.T) {
+	userRepo := UserRepository{dialect: postgresDialect}
+	tests := []struct {
+		name      string
+		err       error
+		want      error
+		wantField string
+	}{
+		{"taken email", &pq.Error{Code: "23505", Constraint: "users_email_lower_key"}, types.ErrEmailTaken, "email"},
+		{"taken username", &pq.Error{Code: "23505", Constraint: "users_username_lower_key"}, types.ErrUsernameTaken, "username"},
+		{"lookalike username", &pq.Error{Code: "23505", Constraint: "users_username_skeleton_key"}, types.ErrUsernameTaken, "username"},
+		{"lookalike username on sqlite", &types.DatabaseError{Kind: types.ErrUniqueViolation, Table: "users", Column: "username_skeleton"}, types.ErrUsernameTaken, "username"},
+		{"other unique constraint", &pq.Error{Code: "23505", Constraint: "users_user_id_key"}, types.ErrDuplicateUser, ""},
+		{"outage", &pq.Error{Code: "57P03"}, types.ErrServiceUnavailable, ""},
+		{"timeout", context.DeadlineExceeded, types.ErrServiceUnavailable, ""},
+		{"client went away", fmt.Errorf("query: %w", context.Canceled), types.ErrRequestCanceled, ""},
+		{"retries exhausted", &pq.Error{Code: "40001"}, types.ErrServiceUnavailable, ""},
+		{"not null", &pq.Error{Code: "23502"}, types.ErrNotNullViolation, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := userRepo.apiError(test.err)
+			if !errors.Is(err, test.want) {
+				t.Errorf("apiError = %v, want %v", err, test.want)
+			}
+			var appError *types.AppError
+			if errors.As(err, &appError) {
+				if field, _ := appError.Extensions["field"].(string); field != test.wantField {
+					t.Errorf("field = %q, want %q", field, test.wantField)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,391 @@
+package repositories
+
+import (
+	"cmp"
+	"context"
+	"fiber-auth-api/internal/types"
+	"fiber-auth-api/internal/validation"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MemoryUserRepository is a UserStore kept in process memory, for tests and
+// local development without Postgres. It mirrors the Postgres repository:
+// emails are unique case-insensitively and usernames by their confusable
+// skeleton, soft-deleted users keep them taken but are otherwise
+// invisible, and listings filter, sort and paginate the same way. Search
+// only matches word prefixes; the trigram fuzzy matching of Postgres has no
+// equivalent here.
+type MemoryUserRepository struct {
+	mu     sync.RWMutex
+	users  map[string]*UserCreateDbModel
+	log    *slog.Logger
+	config UserRepositoryConfig
+}
+
+func NewMemoryUserRepository(log *slog.Logger, config UserRepositoryConfig) *MemoryUserRepository {
+	return &MemoryUserRepository{
+		users:  make(map[string]*UserCreateDbModel),
+		log:    log,
+		config: config,
+	}
+}
+
+func (memoryRepo *MemoryUserRepository) CreateUser(ctx context.Context, user *UserCreateDbModel) error {
+	user.Email = validation.NormalizeEmail(user.Email)
+	user.Username = validation.NormalizeUsername(user.Username)
+
+	memoryRepo.mu.Lock()
+	defer memoryRepo.mu.Unlock()
+
+	if err := memoryRepo.taken(user.Email, user.Username); err != nil {
+		memoryRepo.log.ErrorContext(ctx, "User already exists", "error", err)
+		return err
+	}
+
+	// Postgres keeps timestamps to the microsecond; matching it keeps
+	// cursors built from them comparable.
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	user.UserId = uuid.NewString()
+	user.CreatedAt, user.UpdatedAt = now, now
+
+	stored := *user
+	stored.LastLoginAt, stored.DeletedAt = time.Time{}, time.Time{}
+	memoryRepo.users[stored.UserId] = &stored
+	return nil
+}
+
+func (memoryRepo *MemoryUserRepository) AuthenticateUser(ctx context.Context, email string) (*UserAuthenticateResponseModel, error) {
+	memoryRepo.mu.RLock()
+	defer memoryRepo.mu.RUnlock()
+
+	user := memoryRepo.byEmail(validation.NormalizeEmail(email))
+	if user == nil {
+		return nil, types.ErrUserNotFound
+	}
+	return &UserAuthenticateResponseModel{
+		UserId:       user.UserId,
+		Email:        user.Email,
+		Username:     user.Username,
+		PasswordHash: user.PasswordHash,
+	}, nil
+}
+
+func (memoryRepo *MemoryUserRepository) GetAllUsers(ctx context.Context, filter UserListFilter) ([]*UserResponseModel, *types.Metadata, error) {
+	if filter.PerPage <= 0 {
+		filter.PerPage = memoryRepo.config.PageSize
+	}
+	if filter.Page <= 0 {
+		filter.Page = 1
+	}
+	if filter.SortBy == "" {
+		filter.SortBy, filter.SortDesc = "created_at", true
+	}
+	if _, ok := userSortColumns[filter.SortBy]; !ok {
+		return nil, nil, fmt.Errorf("unsupported sort field %q", filter.SortBy)
+	}
+
+	memoryRepo.mu.RLock()
+	defer memoryRepo.mu.RUnlock()
+
+	matched := memoryRepo.list(filter)
+	slices.SortFunc(matched, func(a, b *UserCreateDbModel) int {
+		// Like NULLS LAST, users who never signed in come last either way.
+		if filter.SortBy == "last_login_at" && a.LastLoginAt.IsZero() != b.LastLoginAt.IsZero() {
+			if a.LastLoginAt.IsZero() {
+				return 1
+			}
+			return -1
+		}
+		order := cmp.Or(compareUserColumn(a, b, filter.SortBy), strings.Compare(a.UserId, b.UserId))
+		if filter.SortDesc {
+			return -order
+		}
+		return order
+	})
+
+	page := pageOf(matched, filter.Offset(), filter.PerPage)
+	users := make([]*UserResponseModel, 0, len(page))
+	for _, user := range page {
+		users = append(users, userResponse(user))
+	}
+	return users, types.NewMetadata(len(matched), filter.Page, filter.PerPage, len(users)), nil
+}
+
+func (memoryRepo *MemoryUserRepository) GetUsersByKeyset(ctx context.Context, filter UserListFilter, after *UserKeyset, backward bool) ([]*UserResponseModel, bool, error) {
+	if filter.PerPage <= 0 {
+		filter.PerPage = memoryRepo.config.PageSize
+	}
+	descending := filter.SortDesc != backward
+
+	memoryRepo.mu.RLock()
+	defer memoryRepo.mu.RUnlock()
+
+	matched := memoryRepo.list(filter)
+	keyset := func(user *UserCreateDbModel) int {
+		return cmp.Or(user.CreatedAt.Compare(after.CreatedAt), strings.Compare(user.UserId, after.UserId))
+	}
+	if after != nil {
+		matched = slices.DeleteFunc(matched, func(user *UserCreateDbModel) bool {
+			if descending {
+				return keyset(user) >= 0
+			}
+			return keyset(user) <= 0
+		})
+	}
+	slices.SortFunc(matched, func(a, b *UserCreateDbModel) int {
+		order := cmp.Or(a.CreatedAt.Compare(b.CreatedAt), strings.Compare(a.UserId, b.UserId))
+		if descending {
+			return -order
+		}
+		return order
+	})
+
+	hasMore := len(matched) > filter.PerPage
+	page := pageOf(matched, 0, filter.PerPage)
+	users := make([]*UserResponseModel, 0, len(page))
+	for _, user := range page {
+		users = append(users, userResponse(user))
+	}
+	if backward {
+		slices.Reverse(users)
+	}
+	return users, hasMore, nil
+}
+
+// searchWeights follow the default ts_rank weights of the fields' A, B and
+// C labels in the search_vector column.
+var searchWeights = map[string]float64{"username": 1, "first_name": 0.4, "last_name": 0.4, "email": 0.2}
+
+func (memoryRepo *MemoryUserRepository) SearchUsers(ctx context.Context, filter UserListFilter, term string) ([]*UserSearchResultModel, *types.Metadata, error) {
+	if filter.PerPage <= 0 {
+		filter.PerPage = memoryRepo.config.PageSize
+	}
+	if filter.Page <= 0 {
+		filter.Page = 1
+	}
+
+	words := searchWords(term)
+	if len(words) == 0 {
+		return []*UserSearchResultModel{}, types.NewMetadata(0, filter.Page, filter.PerPage, 0), nil
+	}
+
+	memoryRepo.mu.RLock()
+	defer memoryRepo.mu.RUnlock()
+
+	var results []*UserSearchResultModel
+	for _, user := range memoryRepo.list(filter) {
+		fields := map[string]string{
+			"username":   user.Username,
+			"first_name": user.FirstName,
+			"last_name":  user.LastName,
+			"email":      user.Email,
+		}
+		// Every word must start a word of some field, as with the
+		// "word:* & ..." query Postgres runs.
+		rank := 0.0
+		for _, word := range words {
+			best := 0.0
+			for name, value := range fields {
+				for _, fieldWord := range searchWords(value) {
+					if strings.HasPrefix(fieldWord, word) {
+						best = max(best, searchWeights[name])
+					}
+				}
+			}
+			if best == 0 {
+				rank = 0
+				break
+			}
+			rank += best
+		}
+		if rank == 0 {
+			continue
+		}
+		result := &UserSearchResultModel{UserResponseModel: *userResponse(user), Rank: rank}
+		result.Highlights = highlight(result, words)
+		results = append(results, result)
+	}
+	slices.SortFunc(results, func(a, b *UserSearchResultModel) int {
+		return cmp.Or(cmp.Compare(b.Rank, a.Rank), strings.Compare(a.UserId, b.UserId))
+	})
+
+	page := append([]*UserSearchResultModel{}, pageOf(results, filter.Offset(), filter.PerPage)...)
+	return page, types.NewMetadata(len(results), filter.Page, filter.PerPage, len(page)), nil
+}
+
+func (memoryRepo *MemoryUserRepository) AutocompleteUsers(ctx context.Context, prefix string, limit int) ([]*UserSuggestionModel, error) {
+	prefix = strings.ToLower(prefix)
+
+	memoryRepo.mu.RLock()
+	defer memoryRepo.mu.RUnlock()
+
+	var matched []*UserCreateDbModel
+	for _, user := range memoryRepo.users {
+		if user.DeletedAt.IsZero() && (strings.HasPrefix(strings.ToLower(user.Username), prefix) || strings.HasPrefix(strings.ToLower(user.Email), prefix)) {
+			matched = append(matched, user)
+		}
+	}
+	// Username matches first, as in the Postgres query.
+	slices.SortFunc(matched, func(a, b *UserCreateDbModel) int {
+		aUsername := strings.HasPrefix(strings.ToLower(a.Username), prefix)
+		bUsername := strings.HasPrefix(strings.ToLower(b.Username), prefix)
+		if aUsername != bUsername {
+			if aUsername {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Or(strings.Compare(strings.ToLower(a.Username), strings.ToLower(b.Username)), strings.Compare(a.UserId, b.UserId))
+	})
+
+	suggestions := make([]*UserSuggestionModel, 0, limit)
+	for _, user := range pageOf(matched, 0, limit) {
+		suggestions = append(suggestions, &UserSuggestionModel{UserId: user.UserId, Username: user.Username, Email: user.Email})
+	}
+	return suggestions, nil
+}
+
+func (memoryRepo *MemoryUserRepository) FindUserById(ctx context.Context, userId string) (*UserResponseModel, error) {
+	memoryRepo.mu.RLock()
+	defer memoryRepo.mu.RUnlock()
+
+	user, ok := memoryRepo.users[userId]
+	if !ok || !user.DeletedAt.IsZero() {
+		return nil, types.ErrUserNotFound
+	}
+	return userResponse(user), nil
+}
+
+func (memoryRepo *MemoryUserRepository) FindUserByEmail(ctx context.Context, email string) (*UserResponseModel, error) {
+	memoryRepo.mu.RLock()
+	defer memoryRepo.mu.RUnlock()
+
+	user := memoryRepo.byEmail(validation.NormalizeEmail(email))
+	if user == nil {
+		return nil, types.ErrUserNotFound
+	}
+	return userResponse(user), nil
+}
+
+func (memoryRepo *MemoryUserRepository) IsUserExists(ctx context.Context, email string, username string) (bool, error) {
+	memoryRepo.mu.RLock()
+	defer memoryRepo.mu.RUnlock()
+
+	return memoryRepo.exists(validation.NormalizeEmail(email), validation.NormalizeUsername(username)), nil
+}
+
+func (memoryRepo *MemoryUserRepository) DeleteUser(ctx context.Context, userId string) error {
+	memoryRepo.mu.Lock()
+	defer memoryRepo.mu.Unlock()
+
+	user, ok := memoryRepo.users[userId]
+	if !ok || !user.DeletedAt.IsZero() {
+		return types.ErrUserNotFound
+	}
+	user.DeletedAt = time.Now().UTC().Truncate(time.Microsecond)
+	user.UpdatedAt = user.DeletedAt
+	return nil
+}
+
+// exists reports whether email or username, or a username that looks like
+// it, is taken, by deleted users too, like the unique indexes in Postgres.
+// The caller holds mu.
+func (memoryRepo *MemoryUserRepository) exists(email string, username string) bool {
+	for _, user := range memoryRepo.users {
+		if strings.EqualFold(user.Email, email) || validation.UsernameSkeleton(user.Username) == validation.UsernameSkeleton(username) {
+			return true
+		}
+	}
+	return false
+}
+
+// taken returns ErrEmailTaken or ErrUsernameTaken when another user,
+// deleted or not, has email or a username that looks like username; email
+// is checked first. The caller holds mu.
+func (memoryRepo *MemoryUserRepository) taken(email string, username string) error {
+	for _, user := range memoryRepo.users {
+		if strings.EqualFold(user.Email, email) {
+			return types.ErrEmailTaken
+		}
+	}
+	for _, user := range memoryRepo.users {
+		if validation.UsernameSkeleton(user.Username) == validation.UsernameSkeleton(username) {
+			return types.ErrUsernameTaken
+		}
+	}
+	return nil
+}
+
+// byEmail returns the live user with email, or nil. The caller holds mu.
+func (memoryRepo *MemoryUserRepository) byEmail(email string) *UserCreateDbModel {
+	for _, user := range memoryRepo.users {
+		if user.DeletedAt.IsZero() && strings.EqualFold(user.Email, email) {
+			return user
+		}
+	}
+	return nil
+}
+
+// list returns the live users matching the filter's conditions, in no
+// particular order. The caller holds mu.
+func (memoryRepo *MemoryUserRepository) list(filter UserListFilter) []*UserCreateDbModel {
+	var users []*UserCreateDbModel
+	for _, user := range memoryRepo.users {
+		switch {
+		case !user.DeletedAt.IsZero(),
+			filter.IsActive != nil && user.IsActive != *filter.IsActive,
+			filter.IsEmailVerified != nil && user.IsEmailVerified != *filter.IsEmailVerified,
+			!filter.CreatedFrom.IsZero() && user.CreatedAt.Before(filter.CreatedFrom),
+			!filter.CreatedTo.IsZero() && !user.CreatedAt.Before(filter.CreatedTo):
+			continue
+		}
+		users = append(users, user)
+	}
+	return users
+}
+
+func compareUserColumn(a *UserCreateDbModel, b *UserCreateDbModel, column string) int {
+	switch column {
+	case "updated_at":
+		return a.UpdatedAt.Compare(b.UpdatedAt)
+	case "last_login_at":
+		return a.LastLoginAt.Compare(b.LastLoginAt)
+	case "username":
+		return strings.Compare(a.Username, b.Username)
+	case "email":
+		return strings.Compare(a.Email, b.Email)
+	case "first_name":
+		return strings.Compare(a.FirstName, b.FirstName)
+	case "last_name":
+		return strings.Compare(a.LastName, b.LastName)
+	}
+	return a.CreatedAt.Compare(b.CreatedAt)
+}
+
+// pageOf is the part of items an OFFSET offset LIMIT limit query returns.
+func pageOf[T any](items []T, offset int, limit int) []T {
+	if offset >= len(items) {
+		return nil
+	}
+	return items[offset:min(offset+limit, len(items))]
+}
+
+func userResponse(user *UserCreateDbModel) *UserResponseModel {
+	return &UserResponseModel{
+		UserId:          user.UserId,
+		Email:           user.Email,
+		Username:        user.Username,
+		FirstName:       user.FirstName,
+		LastName:        user.LastName,
+		IsActive:        user.IsActive,
+		IsEmailVerified: user.IsEmailVerified,
+		CreatedAt:       user.CreatedAt,
+	}
+}
@@ -0,0 +1,23 @@
+package repositories_test
+
+import (
+	"context"
+	"fiber-auth-api/internal/repositories"
+	"fiber-auth-api/internal/repositories/storetest"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+var (
+	testLogger     = slog.New(slog.NewTextHandler(io.Discard, nil))
+	testUserConfig = repositories.UserRepositoryConfig{QueryTimeout: 5 * time.Second, PageSize: 10}
+)
+
+func TestMemoryUserStore(t *testing.T) {
+	store := repositories.NewMemoryUserRepository(testLogger, testUserConfig)
+	if err := storetest.TestUserStore(context.Background(), store); err != nil {
+		t.Fatal(err)
+	}
+}
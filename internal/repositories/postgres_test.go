@@ -0,0 +1,79 @@
+package repositories_test
+
+import (
+	"context"
+	"database/sql"
+	"fiber-auth-api/internal/migrations"
+	"fiber-auth-api/internal/repositories"
+	"fiber-auth-api/internal/repositories/storetest"
+	"fmt"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq"
+)
+
+// postgresDSNEnv names the variable holding the DSN, in URL form, of a
+// Postgres server the tests may create schemas on. Without it the Postgres
+// tests are skipped.
+const postgresDSNEnv = "TEST_POSTGRES_DSN"
+
+// openPostgres returns a connection pool confined to a new, migrated
+// schema, which is dropped when the test ends.
+func openPostgres(t testing.TB) *sql.DB {
+	t.Helper()
+	dsn := os.Getenv(postgresDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", postgresDSNEnv)
+	}
+
+	admin, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("opening %s: %v", postgresDSNEnv, err)
+	}
+	t.Cleanup(func() { admin.Close() })
+
+	ctx := context.Background()
+	schema := fmt.Sprintf("test_%d", time.Now().UnixNano())
+	if _, err := admin.ExecContext(ctx, "CREATE SCHEMA "+schema); err != nil {
+		t.Fatalf("creating schema: %v", err)
+	}
+	t.Cleanup(func() {
+		if _, err := admin.ExecContext(ctx, "DROP SCHEMA "+schema+" CASCADE"); err != nil {
+			t.Errorf("dropping schema: %v", err)
+		}
+	})
+
+	// Extensions already installed in public stay visible.
+	schemaDSN, err := url.Parse(dsn)
+	if err != nil {
+		t.Fatalf("parsing %s: %v", postgresDSNEnv, err)
+	}
+	query := schemaDSN.Query()
+	query.Set("search_path", schema+",public")
+	schemaDSN.RawQuery = query.Encode()
+
+	db, err := sql.Open("postgres", schemaDSN.String())
+	if err != nil {
+		t.Fatalf("opening schema: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := migrations.NewMigrator(db, testLogger)
+	if err != nil {
+		t.Fatalf("NewMigrator: %v", err)
+	}
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatalf("migrating: %v", err)
+	}
+	return db
+}
+
+func TestPostgresUserStore(t *testing.T) {
+	store := repositories.NewUserRepository(openPostgres(t), testLogger, testUserConfig)
+	if err := storetest.TestUserStore(context.Background(), store); err != nil {
+		t.Fatal(err)
+	}
+}
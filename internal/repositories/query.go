@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// whereClause collects AND-ed conditions with their arguments. Conditions
+// are written with ? for each argument, which is numbered as $n when the
+// clause is rendered, so values never end up in the SQL text.
+type whereClause struct {
+	conditions []string
+	args       []any
+}
+
+func (where *whereClause) add(condition string, args ...any) {
+	var numbered strings.Builder
+	next := 0
+	for _, r := range condition {
+		if r == '?' && next < len(args) {
+			where.args = append(where.args, args[next])
+			numbered.WriteString("$" + strconv.Itoa(len(where.args)))
+			next++
+			continue
+		}
+		numbered.WriteRune(r)
+	}
+	where.conditions = append(where.conditions, numbered.String())
+}
+
+// arg appends an argument that is not part of a condition, such as a limit,
+// and returns its placeholder.
+func (where *whereClause) arg(value any) string {
+	where.args = append(where.args, value)
+	return "$" + strconv.Itoa(len(where.args))
+}
+
+func (where *whereClause) String() string {
+	if len(where.conditions) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(where.conditions, " AND ")
+}
+
+// pageOffset is the number of rows before page when pages hold perPage rows.
+// An offset too large for an int is clamped to math.MaxInt, which still
+// skips every row, rather than wrapping around to a negative OFFSET.
+func pageOffset(page int, perPage int) int {
+	if page <= 1 || perPage <= 0 {
+		return 0
+	}
+	if page-1 > math.MaxInt/perPage {
+		return math.MaxInt
+	}
+	return (page - 1) * perPage
+}
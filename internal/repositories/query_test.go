@@ -0,0 +1,52 @@
+package repositories
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestWhereClause(t *testing.T) {
+	var where whereClause
+	if got := where.String(); got != "" {
+		t.Errorf("empty clause = %q, want nothing", got)
+	}
+
+	where.add("deleted_at IS NULL")
+	where.add("is_active = ?", true)
+	where.add("created_at >= ? AND created_at < ?", "from", "to")
+	// A ? without an argument is left for the database, e.g. a jsonb operator.
+	where.add("metadata ? 'reason'")
+	limit := where.arg(10)
+
+	want := "WHERE deleted_at IS NULL AND is_active = $1 AND created_at >= $2 AND created_at < $3 AND metadata ? 'reason'"
+	if got := where.String(); got != want {
+		t.Errorf("String = %q, want %q", got, want)
+	}
+	if limit != "$4" {
+		t.Errorf("arg = %q, want $4", limit)
+	}
+	if !slices.Equal(where.args, []any{true, "from", "to", 10}) {
+		t.Errorf("args = %v", where.args)
+	}
+}
+
+func TestPageOffset(t *testing.T) {
+	tests := []struct {
+		page, perPage int
+		want          int
+	}{
+		{0, 10, 0},
+		{1, 10, 0},
+		{3, 10, 20},
+		{5, 0, 0},
+		{math.MaxInt, 100, math.MaxInt},
+		{2, math.MaxInt, math.MaxInt},
+		{3, math.MaxInt / 2, math.MaxInt - 1},
+	}
+	for _, test := range tests {
+		if got := pageOffset(test.page, test.perPage); got != test.want {
+			t.Errorf("pageOffset(%d, %d) = %d, want %d", test.page, test.perPage, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package repositories
+
+import (
+	"context"
+	"fiber-auth-api/internal/types"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// UserSearchResultModel is a user matched by SearchUsers. Rank orders the
+// results, higher first; Highlights point at the parts of each field that
+// matched the search words.
+type UserSearchResultModel struct {
+	UserResponseModel
+	Rank       float64     `json:"rank"`
+	Highlights []Highlight `json:"highlights"`
+}
+
+// Highlight is a match inside Field, Start and Length counted in characters.
+// Ranges rather than markup keep user data out of any HTML the client
+// renders.
+type Highlight struct {
+	Field  string `json:"field"`
+	Start  int    `json:"start"`
+	Length int    `json:"length"`
+}
+
+type UserSuggestionModel struct {
+	UserId   string `json:"user_id"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+}
+
+// searchWords lowercases term and splits it into words of letters and
+// digits. Everything else separates words, so the words are safe to join
+// into a to_tsquery expression.
+func searchWords(term string) []string {
+	return strings.FieldsFunc(strings.Map(unicode.ToLower, term), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// SearchUsers finds users whose username, name or email match term, either
+// as word prefixes through the full-text index or approximately through
+// trigram similarity, so "jon smi" finds "John Smith". Results are ordered
+// by relevance; the filter's sort order is not used.
+func (userRepo UserRepository) SearchUsers(ctx context.Context, filter UserListFilter, term string) (results []*UserSearchResultModel, metadata *types.Metadata, err error) {
+	ctx, span := startQuerySpan(ctx, userRepo.dialect.system, "UserRepository", "SearchUsers")
+	defer func() { endQuerySpan(span, len(results), err) }()
+
+	if filter.PerPage <= 0 {
+		filter.PerPage = userRepo.config.PageSize
+	}
+	if filter.Page <= 0 {
+		filter.Page = 1
+	}
+
+	words := searchWords(term)
+	if len(words) == 0 {
+		return []*UserSearchResultModel{}, types.NewMetadata(0, filter.Page, filter.PerPage, 0), nil
+	}
+	prefixes := make([]string, len(words))
+	for i, word := range words {
+		prefixes[i] = word + ":*"
+	}
+	tsquery := strings.Join(prefixes, " & ")
+	phrase := strings.Join(words, " ")
+
+	ctx, cancel := context.WithTimeout(ctx, userRepo.config.QueryTimeout)
+	defer cancel()
+
+	where := userListWhere(filter, userRepo.dialect)
+	queryArg, phraseArg := where.arg(tsquery), where.arg(phrase)
+	where.add(fmt.Sprintf(`(search_vector @@ to_tsquery('simple', %[1]s)
+		OR lower(username) %% %[2]s
+		OR lower(email) %% %[2]s
+		OR %[2]s <%% lower(first_name || ' ' || last_name))`, queryArg, phraseArg))
+
+	var totalRecords int
+	if err = userRepo.DB.QueryRowContext(ctx, `SELECT count(*) FROM users `+where.String(), where.args...).Scan(&totalRecords); err != nil {
+		userRepo.log.ErrorContext(ctx, "Failed to count user search results", "error", err)
+		return nil, nil, userRepo.apiError(err)
+	}
+
+	// Full-text matches outrank fuzzy ones: ts_rank is weighted by field and
+	// the best trigram similarity breaks ties and ranks typo matches.
+	query := fmt.Sprintf(`
+		SELECT user_id, email, first_name, last_name, username, is_email_verified, is_active, created_at,
+			ts_rank(search_vector, to_tsquery('simple', %[2]s)) * 2 + greatest(
+				similarity(lower(username), %[3]s),
+				similarity(lower(email), %[3]s),
+				word_similarity(%[3]s, lower(first_name || ' ' || last_name))
+			) AS rank
+		FROM users %[1]s
+		ORDER BY rank DESC, user_id
+		LIMIT %[4]s OFFSET %[5]s`,
+		where.String(), queryArg, phraseArg,
+		where.arg(filter.PerPage), where.arg(filter.Offset()))
+
+	rows, err := userRepo.DB.QueryContext(ctx, query, where.args...)
+	if err != nil {
+		userRepo.log.ErrorContext(ctx, "Failed to search users", "error", err)
+		return nil, nil, userRepo.apiError(err)
+	}
+	defer rows.Close()
+
+	results = make([]*UserSearchResultModel, 0, min(filter.PerPage, totalRecords))
+	for rows.Next() {
+		result := &UserSearchResultModel{}
+		err = rows.Scan(
+			&result.UserId,
+			&result.Email,
+			&result.FirstName,
+			&result.LastName,
+			&result.Username,
+			&result.IsEmailVerified,
+			&result.IsActive,
+			&result.CreatedAt,
+			&result.Rank,
+		)
+		if err != nil {
+			userRepo.log.ErrorContext(ctx, "Failed to scan user search result", "error", err)
+			return nil, nil, userRepo.apiError(err)
+		}
+		result.Highlights = highlight(result, words)
+		results = append(results, result)
+	}
+	if err = rows.Err(); err != nil {
+		userRepo.log.ErrorContext(ctx, "Failed to search users", "error", err)
+		return nil, nil, userRepo.apiError(err)
+	}
+
+	return results, types.NewMetadata(totalRecords, filter.Page, filter.PerPage, len(results)), nil
+}
+
+// AutocompleteUsers suggests users whose username or email starts with
+// prefix, for admin user pickers.
+func (userRepo UserRepository) AutocompleteUsers(ctx context.Context, prefix string, limit int) (suggestions []*UserSuggestionModel, err error) {
+	ctx, span := startQuerySpan(ctx, userRepo.dialect.system, "UserRepository", "AutocompleteUsers")
+	defer func() { endQuerySpan(span, len(suggestions), err) }()
+
+	ctx, cancel := context.WithTimeout(ctx, userRepo.config.QueryTimeout)
+	defer cancel()
+
+	// LIKE metacharacters in the prefix must match literally.
+	pattern := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(strings.ToLower(prefix)) + "%"
+
+	query := `
+		SELECT user_id, username, email
+		FROM users
+		WHERE deleted_at IS NULL AND (lower(username) LIKE $1 OR lower(email) LIKE $1)
+		ORDER BY lower(username) LIKE $1 DESC, lower(username), user_id
+		LIMIT $2`
+
+	rows, err := userRepo.DB.QueryContext(ctx, query, pattern, limit)
+	if err != nil {
+		userRepo.log.ErrorContext(ctx, "Failed to autocomplete users", "error", err)
+		return nil, userRepo.apiError(err)
+	}
+	defer rows.Close()
+
+	suggestions = make([]*UserSuggestionModel, 0, limit)
+	for rows.Next() {
+		suggestion := &UserSuggestionModel{}
+		if err = rows.Scan(&suggestion.UserId, &suggestion.Username, &suggestion.Email); err != nil {
+			userRepo.log.ErrorContext(ctx, "Failed to scan user suggestion", "error", err)
+			return nil, userRepo.apiError(err)
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+	if err = rows.Err(); err != nil {
+		userRepo.log.ErrorContext(ctx, "Failed to autocomplete users", "error", err)
+		return nil, userRepo.apiError(err)
+	}
+	return suggestions, nil
+}
+
+// highlight finds every case-insensitive occurrence of the search words in
+// the searchable fields. Fuzzy matches that share no word with the term
+// have no highlights.
+func highlight(result *UserSearchResultModel, words []string) []Highlight {
+	fields := []struct {
+		name  string
+		value string
+	}{
+		{"username", result.Username},
+		{"email", result.Email},
+		{"first_name", result.FirstName},
+		{"last_name", result.LastName},
+	}
+
+	highlights := make([]Highlight, 0)
+	for _, field := range fields {
+		// Lowercasing rune by rune keeps character offsets aligned with
+		// the original value.
+		value := []rune(strings.Map(unicode.ToLower, field.value))
+		for _, word := range words {
+			needle := []rune(word)
+			for start := 0; start+len(needle) <= len(value); start++ {
+				if string(value[start:start+len(needle)]) == word {
+					highlights = append(highlights, Highlight{Field: field.name, Start: start, Length: len(needle)})
+					start += len(needle) - 1
+				}
+			}
+		}
+	}
+	return highlights
+}
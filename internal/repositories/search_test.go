@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestSearchWords(t *testing.T) {
+	tests := []struct {
+		term string
+		want []string
+	}{
+		{"", nil},
+		{"  !!  ", nil},
+		{"Jon Smi", []string{"jon", "smi"}},
+		{"jane.doe@example.com", []string{"jane", "doe", "example", "com"}},
+		// tsquery operators are separators, never part of a word.
+		{"a & !b | c:* <-> 'd'", []string{"a", "b", "c", "d"}},
+		{"ZOË o'brien 42", []string{"zoë", "o", "brien", "42"}},
+	}
+	for _, test := range tests {
+		if got := searchWords(test.term); !slices.Equal(got, test.want) {
+			t.Errorf("searchWords(%q) = %q, want %q", test.term, got, test.want)
+		}
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	result := &UserSearchResultModel{UserResponseModel: UserResponseModel{
+		Username:  "JonJon",
+		Email:     "jon.smith@example.com",
+		FirstName: "Zoë",
+		LastName:  "Smith",
+	}}
+
+	tests := []struct {
+		name  string
+		words []string
+		want  []string
+	}{
+		{"no words", nil, nil},
+		{"fuzzy match without a shared word", []string{"john"}, nil},
+		{
+			name:  "every occurrence in every field",
+			words: []string{"jon"},
+			want:  []string{"username 0+3", "username 3+3", "email 0+3"},
+		},
+		{
+			name:  "several words",
+			words: []string{"smi", "jon"},
+			want:  []string{"username 0+3", "username 3+3", "email 4+3", "email 0+3", "last_name 0+3"},
+		},
+		{
+			name:  "offsets count characters, not bytes",
+			words: []string{"ë"},
+			want:  []string{"first_name 2+1"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got []string
+			for _, h := range highlight(result, test.words) {
+				got = append(got, fmt.Sprintf("%s %d+%d", h.Field, h.Start, h.Length))
+			}
+			if !slices.Equal(got, test.want) {
+				t.Errorf("highlight(%q) = %q, want %q", test.words, got, test.want)
+			}
+		})
+	}
+
+	overlapping := &UserSearchResultModel{UserResponseModel: UserResponseModel{Username: "aaaaa"}}
+	if got := highlight(overlapping, []string{"aa"}); len(got) != 2 || got[0].Start != 0 || got[1].Start != 2 {
+		t.Errorf("overlapping occurrences were highlighted as %+v, want starts 0 and 2", got)
+	}
+}
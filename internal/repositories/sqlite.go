@@ -0,0 +1,153 @@
+package repositories
+
+import (
+	"context"
+	"fiber-auth-api/internal/types"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// SqliteUserRepository is a UserStore on a SQLite database migrated with
+// the SQLite migrations. It shares the statements of UserRepository that
+// both databases understand; search uses an FTS5 index instead of
+// tsvector and trigrams, so it matches word prefixes but not misspellings.
+type SqliteUserRepository struct {
+	UserRepository
+}
+
+func NewSqliteUserRepository(db DBTX, log *slog.Logger, config UserRepositoryConfig) *SqliteUserRepository {
+	return &SqliteUserRepository{UserRepository{
+		DB:      db,
+		log:     log,
+		config:  config,
+		dialect: sqliteDialect,
+	}}
+}
+
+// SearchUsers finds users with a word starting with each word of term in
+// their username, name or email. Results are ordered by bm25 relevance,
+// weighting the fields like the Postgres search vector.
+func (userRepo SqliteUserRepository) SearchUsers(ctx context.Context, filter UserListFilter, term string) (results []*UserSearchResultModel, metadata *types.Metadata, err error) {
+	ctx, span := startQuerySpan(ctx, userRepo.dialect.system, "UserRepository", "SearchUsers")
+	defer func() { endQuerySpan(span, len(results), err) }()
+
+	if filter.PerPage <= 0 {
+		filter.PerPage = userRepo.config.PageSize
+	}
+	if filter.Page <= 0 {
+		filter.Page = 1
+	}
+
+	words := searchWords(term)
+	if len(words) == 0 {
+		return []*UserSearchResultModel{}, types.NewMetadata(0, filter.Page, filter.PerPage, 0), nil
+	}
+	// Words hold only letters and digits, so quoting them is enough to keep
+	// FTS5 from reading them as operators.
+	prefixes := make([]string, len(words))
+	for i, word := range words {
+		prefixes[i] = `"` + word + `"*`
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, userRepo.config.QueryTimeout)
+	defer cancel()
+
+	where := userListWhere(filter, userRepo.dialect)
+	where.add("users_search MATCH ?", strings.Join(prefixes, " "))
+	from := `FROM users_search JOIN users ON users.id = users_search.rowid ` + where.String()
+
+	var totalRecords int
+	if err = userRepo.DB.QueryRowContext(ctx, `SELECT count(*) `+from, where.args...).Scan(&totalRecords); err != nil {
+		userRepo.log.ErrorContext(ctx, "Failed to count user search results", "error", err)
+		return nil, nil, userRepo.apiError(err)
+	}
+
+	// bm25 scores better matches lower; negating it ranks higher first as
+	// in Postgres.
+	query := fmt.Sprintf(`
+		SELECT users.user_id, users.email, users.first_name, users.last_name, users.username,
+			users.is_email_verified, users.is_active, users.created_at,
+			-bm25(users_search, 1.0, 0.4, 0.4, 0.2) AS rank
+		%s
+		ORDER BY rank DESC, users.user_id
+		LIMIT %s OFFSET %s`,
+		from, where.arg(filter.PerPage), where.arg(filter.Offset()))
+
+	rows, err := userRepo.DB.QueryContext(ctx, query, where.args...)
+	if err != nil {
+		userRepo.log.ErrorContext(ctx, "Failed to search users", "error", err)
+		return nil, nil, userRepo.apiError(err)
+	}
+	defer rows.Close()
+
+	results = make([]*UserSearchResultModel, 0, min(filter.PerPage, totalRecords))
+	for rows.Next() {
+		result := &UserSearchResultModel{}
+		err = rows.Scan(
+			&result.UserId,
+			&result.Email,
+			&result.FirstName,
+			&result.LastName,
+			&result.Username,
+			&result.IsEmailVerified,
+			&result.IsActive,
+			&result.CreatedAt,
+			&result.Rank,
+		)
+		if err != nil {
+			userRepo.log.ErrorContext(ctx, "Failed to scan user search result", "error", err)
+			return nil, nil, userRepo.apiError(err)
+		}
+		result.Highlights = highlight(result, words)
+		results = append(results, result)
+	}
+	if err = rows.Err(); err != nil {
+		userRepo.log.ErrorContext(ctx, "Failed to search users", "error", err)
+		return nil, nil, userRepo.apiError(err)
+	}
+
+	return results, types.NewMetadata(totalRecords, filter.Page, filter.PerPage, len(results)), nil
+}
+
+// AutocompleteUsers suggests users whose username or email starts with
+// prefix. Unlike Postgres, SQLite needs the LIKE escape character spelled
+// out.
+func (userRepo SqliteUserRepository) AutocompleteUsers(ctx context.Context, prefix string, limit int) (suggestions []*UserSuggestionModel, err error) {
+	ctx, span := startQuerySpan(ctx, userRepo.dialect.system, "UserRepository", "AutocompleteUsers")
+	defer func() { endQuerySpan(span, len(suggestions), err) }()
+
+	ctx, cancel := context.WithTimeout(ctx, userRepo.config.QueryTimeout)
+	defer cancel()
+
+	pattern := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(strings.ToLower(prefix)) + "%"
+
+	query := `
+		SELECT user_id, username, email
+		FROM users
+		WHERE deleted_at IS NULL AND (lower(username) LIKE $1 ESCAPE '\' OR lower(email) LIKE $1 ESCAPE '\')
+		ORDER BY lower(username) LIKE $1 ESCAPE '\' DESC, lower(username), user_id
+		LIMIT $2`
+
+	rows, err := userRepo.DB.QueryContext(ctx, query, pattern, limit)
+	if err != nil {
+		userRepo.log.ErrorContext(ctx, "Failed to autocomplete users", "error", err)
+		return nil, userRepo.apiError(err)
+	}
+	defer rows.Close()
+
+	suggestions = make([]*UserSuggestionModel, 0, limit)
+	for rows.Next() {
+		suggestion := &UserSuggestionModel{}
+		if err = rows.Scan(&suggestion.UserId, &suggestion.Username, &suggestion.Email); err != nil {
+			userRepo.log.ErrorContext(ctx, "Failed to scan user suggestion", "error", err)
+			return nil, userRepo.apiError(err)
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+	if err = rows.Err(); err != nil {
+		userRepo.log.ErrorContext(ctx, "Failed to autocomplete users", "error", err)
+		return nil, userRepo.apiError(err)
+	}
+	return suggestions, nil
+}
@@ -0,0 +1,41 @@
+package repositories_test
+
+import (
+	"context"
+	"database/sql"
+	"fiber-auth-api/internal/database"
+	"fiber-auth-api/internal/migrations"
+	"fiber-auth-api/internal/repositories"
+	"fiber-auth-api/internal/repositories/storetest"
+	"path/filepath"
+	"testing"
+)
+
+// openSqlite returns a migrated database in a temporary directory.
+func openSqlite(t *testing.T) *sql.DB {
+	t.Helper()
+	config := database.DefaultSqliteConfig()
+	config.Path = filepath.Join(t.TempDir(), "test.db")
+	sqliteDatabase, err := database.NewSqliteDatabase(config)
+	if err != nil {
+		t.Fatalf("NewSqliteDatabase: %v", err)
+	}
+	t.Cleanup(func() { sqliteDatabase.CloseSqliteDb() })
+
+	db := sqliteDatabase.GetSqliteDB()
+	migrator, err := migrations.NewSqliteMigrator(db, testLogger)
+	if err != nil {
+		t.Fatalf("NewSqliteMigrator: %v", err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatalf("migrating: %v", err)
+	}
+	return db
+}
+
+func TestSqliteUserStore(t *testing.T) {
+	store := repositories.NewSqliteUserRepository(openSqlite(t), testLogger, testUserConfig)
+	if err := storetest.TestUserStore(context.Background(), store); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,33 @@
+package repositories
+
+import (
+	"context"
+	"fiber-auth-api/internal/types"
+)
+
+// UserStore is the user storage used by the handlers. UserRepository
+// implements it on Postgres, SqliteUserRepository on SQLite and
+// MemoryUserRepository in memory; all report a taken email or username as
+// types.ErrEmailTaken or types.ErrUsernameTaken, which match
+// types.ErrDuplicateUser, and missing or deleted users as
+// types.ErrUserNotFound. The database backends report a database that
+// cannot answer as types.ErrServiceUnavailable. The storetest package
+// checks that they agree.
+type UserStore interface {
+	CreateUser(ctx context.Context, user *UserCreateDbModel) error
+	AuthenticateUser(ctx context.Context, email string) (*UserAuthenticateResponseModel, error)
+	GetAllUsers(ctx context.Context, filter UserListFilter) ([]*UserResponseModel, *types.Metadata, error)
+	GetUsersByKeyset(ctx context.Context, filter UserListFilter, after *UserKeyset, backward bool) ([]*UserResponseModel, bool, error)
+	SearchUsers(ctx context.Context, filter UserListFilter, term string) ([]*UserSearchResultModel, *types.Metadata, error)
+	AutocompleteUsers(ctx context.Context, prefix string, limit int) ([]*UserSuggestionModel, error)
+	FindUserById(ctx context.Context, userId string) (*UserResponseModel, error)
+	FindUserByEmail(ctx context.Context, email string) (*UserResponseModel, error)
+	IsUserExists(ctx context.Context, email string, username string) (bool, error)
+	DeleteUser(ctx context.Context, userId string) error
+}
+
+var (
+	_ UserStore = (*UserRepository)(nil)
+	_ UserStore = (*SqliteUserRepository)(nil)
+	_ UserStore = (*MemoryUserRepository)(nil)
+)
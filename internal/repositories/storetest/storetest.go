@@ -0,0 +1,305 @@
+// Package storetest checks that a repositories.UserStore behaves like the
+// others, in the manner of testing/fstest. Every backend runs the same
+// suite, so the handlers can rely on the behavior whatever the database.
+package storetest
+
+import (
+	"context"
+	"errors"
+	"fiber-auth-api/internal/repositories"
+	"fiber-auth-api/internal/types"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// unknownUserId is a well-formed UUID that no backend generates in practice,
+// so Postgres reports it missing rather than rejecting its syntax.
+const unknownUserId = "00000000-0000-4000-8000-000000000000"
+
+// TestUserStore runs the conformance suite against store, which must be
+// empty and is left holding the users the suite created. It returns every
+// failed check joined into one error, or nil.
+func TestUserStore(ctx context.Context, store repositories.UserStore) error {
+	t := &suite{ctx: ctx, store: store}
+
+	users := t.createUsers()
+	if t.failed() {
+		return t.err()
+	}
+
+	t.testDuplicates()
+	t.testLookups(users)
+	t.testListing(users)
+	t.testKeyset()
+	t.testSearch(users)
+	t.testAutocomplete(users)
+	t.testDelete(users)
+	return t.err()
+}
+
+type suite struct {
+	ctx    context.Context
+	store  repositories.UserStore
+	errors []error
+}
+
+func (t *suite) errorf(format string, args ...any) {
+	t.errors = append(t.errors, fmt.Errorf(format, args...))
+}
+
+func (t *suite) failed() bool {
+	return len(t.errors) > 0
+}
+
+func (t *suite) err() error {
+	return errors.Join(t.errors...)
+}
+
+var fixtures = []repositories.UserCreateDbModel{
+	{Email: "Alice@Example.COM", Username: "alice", FirstName: "Alice", LastName: "Smith", IsActive: true},
+	{Email: "bob@example.com", Username: "bob_builder", FirstName: "Bob", LastName: "Stone", IsActive: true},
+	{Email: "carol@example.org", Username: "carol", FirstName: "Carol", LastName: "Smithers", IsActive: false},
+	{Email: "dave@example.net", Username: "abcd", FirstName: "Dave", LastName: "Jones", IsActive: true},
+}
+
+func (t *suite) createUsers() []*repositories.UserCreateDbModel {
+	users := make([]*repositories.UserCreateDbModel, 0, len(fixtures))
+	for _, fixture := range fixtures {
+		user := fixture
+		user.PasswordHash = "hash-of-" + user.Username
+		if err := t.store.CreateUser(t.ctx, &user); err != nil {
+			t.errorf("CreateUser(%s): %v", user.Username, err)
+			continue
+		}
+		if user.UserId == "" || user.CreatedAt.IsZero() {
+			t.errorf("CreateUser(%s) did not set user_id and created_at", user.Username)
+		}
+		users = append(users, &user)
+		// Distinct creation times keep the expected orders deterministic.
+		time.Sleep(2 * time.Millisecond)
+	}
+	if len(users) > 0 && users[0].Email != "Alice@example.com" {
+		t.errorf("CreateUser stored email %q, want the domain lowercased", users[0].Email)
+	}
+	return users
+}
+
+func (t *suite) testDuplicates() {
+	for _, test := range []struct {
+		duplicate repositories.UserCreateDbModel
+		field     string
+	}{
+		{repositories.UserCreateDbModel{Email: "ALICE@example.com", Username: "someone", FirstName: "A", LastName: "B", PasswordHash: "x"}, "email"},
+		{repositories.UserCreateDbModel{Email: "someone@example.com", Username: "Alice", FirstName: "A", LastName: "B", PasswordHash: "x"}, "username"},
+		// A Cyrillic а and a digit 1 make a username that looks like another.
+		{repositories.UserCreateDbModel{Email: "someone@example.com", Username: "аlice", FirstName: "A", LastName: "B", PasswordHash: "x"}, "username"},
+		{repositories.UserCreateDbModel{Email: "someone@example.com", Username: "a1ice", FirstName: "A", LastName: "B", PasswordHash: "x"}, "username"},
+	} {
+		err := t.store.CreateUser(t.ctx, &test.duplicate)
+		if !errors.Is(err, types.ErrDuplicateUser) || takenField(err) != test.field {
+			t.errorf("CreateUser(%s, %s) = %v, want ErrDuplicateUser naming %s", test.duplicate.Email, test.duplicate.Username, err, test.field)
+		}
+	}
+
+	if exists, err := t.store.IsUserExists(t.ctx, "nobody@example.com", "ALICE"); err != nil || !exists {
+		t.errorf("IsUserExists by username = %v, %v, want true", exists, err)
+	}
+	if exists, err := t.store.IsUserExists(t.ctx, "nobody@example.com", "аlice"); err != nil || !exists {
+		t.errorf("IsUserExists by a confusable username = %v, %v, want true", exists, err)
+	}
+	if exists, err := t.store.IsUserExists(t.ctx, "nobody@example.com", "nobody"); err != nil || exists {
+		t.errorf("IsUserExists of unknown user = %v, %v, want false", exists, err)
+	}
+}
+
+func (t *suite) testLookups(users []*repositories.UserCreateDbModel) {
+	alice := users[0]
+
+	found, err := t.store.FindUserById(t.ctx, alice.UserId)
+	if err != nil || found.Username != alice.Username {
+		t.errorf("FindUserById = %v, %v, want %s", found, err, alice.Username)
+	}
+	if _, err := t.store.FindUserById(t.ctx, unknownUserId); !errors.Is(err, types.ErrUserNotFound) {
+		t.errorf("FindUserById of unknown user = %v, want ErrUserNotFound", err)
+	}
+
+	found, err = t.store.FindUserByEmail(t.ctx, "alice@EXAMPLE.com")
+	if err != nil || found.UserId != alice.UserId {
+		t.errorf("FindUserByEmail ignoring case = %v, %v, want %s", found, err, alice.UserId)
+	}
+	if _, err := t.store.FindUserByEmail(t.ctx, "nobody@example.com"); !errors.Is(err, types.ErrUserNotFound) {
+		t.errorf("FindUserByEmail of unknown user = %v, want ErrUserNotFound", err)
+	}
+
+	authenticated, err := t.store.AuthenticateUser(t.ctx, "ALICE@example.com")
+	if err != nil || authenticated.PasswordHash != alice.PasswordHash {
+		t.errorf("AuthenticateUser = %v, %v, want the stored password hash", authenticated, err)
+	}
+	if _, err := t.store.AuthenticateUser(t.ctx, "nobody@example.com"); !errors.Is(err, types.ErrUserNotFound) {
+		t.errorf("AuthenticateUser of unknown user = %v, want ErrUserNotFound", err)
+	}
+}
+
+func (t *suite) testListing(users []*repositories.UserCreateDbModel) {
+	listed, metadata, err := t.store.GetAllUsers(t.ctx, repositories.UserListFilter{Page: 2, PerPage: 2, SortBy: "username"})
+	if err != nil {
+		t.errorf("GetAllUsers: %v", err)
+		return
+	}
+	if got := usernames(listed); !slices.Equal(got, []string{"bob_builder", "carol"}) {
+		t.errorf("GetAllUsers page 2 by username = %v, want [bob_builder carol]", got)
+	}
+	if metadata.TotalRecords != len(users) || metadata.TotalPages != 2 {
+		t.errorf("GetAllUsers metadata = %+v, want %d records on 2 pages", metadata, len(users))
+	}
+
+	inactive := false
+	listed, _, err = t.store.GetAllUsers(t.ctx, repositories.UserListFilter{IsActive: &inactive, PerPage: 10})
+	if err != nil || !slices.Equal(usernames(listed), []string{"carol"}) {
+		t.errorf("GetAllUsers of inactive users = %v, %v, want [carol]", usernames(listed), err)
+	}
+
+	listed, _, err = t.store.GetAllUsers(t.ctx, repositories.UserListFilter{CreatedFrom: users[1].CreatedAt, CreatedTo: users[3].CreatedAt, PerPage: 10})
+	if err != nil || !slices.Equal(usernames(listed), []string{"carol", "bob_builder"}) {
+		t.errorf("GetAllUsers between creation times = %v, %v, want [carol bob_builder]", usernames(listed), err)
+	}
+}
+
+func (t *suite) testKeyset() {
+	all, _, err := t.store.GetAllUsers(t.ctx, repositories.UserListFilter{PerPage: 10})
+	if err != nil {
+		t.errorf("GetAllUsers: %v", err)
+		return
+	}
+	want := usernames(all)
+
+	filter := repositories.UserListFilter{PerPage: 3, SortDesc: true}
+	first, hasMore, err := t.store.GetUsersByKeyset(t.ctx, filter, nil, false)
+	if err != nil || !hasMore || !slices.Equal(usernames(first), want[:3]) {
+		t.errorf("GetUsersByKeyset first page = %v, %v, %v, want %v and more", usernames(first), hasMore, err, want[:3])
+		return
+	}
+
+	last := first[len(first)-1]
+	second, hasMore, err := t.store.GetUsersByKeyset(t.ctx, filter, &repositories.UserKeyset{CreatedAt: last.CreatedAt, UserId: last.UserId}, false)
+	if err != nil || hasMore || !slices.Equal(usernames(second), want[3:]) {
+		t.errorf("GetUsersByKeyset second page = %v, %v, %v, want %v and no more", usernames(second), hasMore, err, want[3:])
+		return
+	}
+
+	back, hasMore, err := t.store.GetUsersByKeyset(t.ctx, filter, &repositories.UserKeyset{CreatedAt: second[0].CreatedAt, UserId: second[0].UserId}, true)
+	if err != nil || hasMore || !slices.Equal(usernames(back), want[:3]) {
+		t.errorf("GetUsersByKeyset backward = %v, %v, %v, want %v and no more", usernames(back), hasMore, err, want[:3])
+	}
+}
+
+func (t *suite) testSearch(users []*repositories.UserCreateDbModel) {
+	results, metadata, err := t.store.SearchUsers(t.ctx, repositories.UserListFilter{PerPage: 10}, "ali smi")
+	if err != nil {
+		t.errorf("SearchUsers: %v", err)
+		return
+	}
+	if len(results) == 0 || results[0].UserId != users[0].UserId {
+		t.errorf("SearchUsers(ali smi) did not rank alice first")
+	} else if !slices.Contains(results[0].Highlights, repositories.Highlight{Field: "username", Start: 0, Length: 3}) {
+		t.errorf("SearchUsers(ali smi) highlights = %v, want the username prefix", results[0].Highlights)
+	}
+	if metadata.TotalRecords != len(results) {
+		t.errorf("SearchUsers metadata counts %d records for %d results", metadata.TotalRecords, len(results))
+	}
+
+	results, _, err = t.store.SearchUsers(t.ctx, repositories.UserListFilter{PerPage: 10}, "smith")
+	if err != nil || len(results) != 2 {
+		t.errorf("SearchUsers(smith) = %d results, %v, want Smith and Smithers", len(results), err)
+	}
+}
+
+func (t *suite) testAutocomplete(users []*repositories.UserCreateDbModel) {
+	suggestions, err := t.store.AutocompleteUsers(t.ctx, "B", 10)
+	if err != nil || len(suggestions) != 1 || suggestions[0].Username != "bob_builder" {
+		t.errorf("AutocompleteUsers(B) = %v, %v, want bob_builder", suggestions, err)
+	}
+	// _ must match itself, not any character.
+	if suggestions, err := t.store.AutocompleteUsers(t.ctx, "bob_b", 10); err != nil || len(suggestions) != 1 {
+		t.errorf("AutocompleteUsers(bob_b) = %v, %v, want bob_builder", suggestions, err)
+	}
+	if suggestions, err := t.store.AutocompleteUsers(t.ctx, "a_c", 10); err != nil || len(suggestions) != 0 {
+		t.errorf("AutocompleteUsers(a_c) = %v, %v, want no match for abcd", suggestions, err)
+	}
+	// Username matches come before email matches.
+	suggestions, err = t.store.AutocompleteUsers(t.ctx, "a", 10)
+	if got := suggestionNames(suggestions); err != nil || !slices.Equal(got, []string{"abcd", "alice"}) {
+		t.errorf("AutocompleteUsers(a) = %v, %v, want [abcd alice]", got, err)
+	}
+}
+
+func (t *suite) testDelete(users []*repositories.UserCreateDbModel) {
+	bob := users[1]
+	if err := t.store.DeleteUser(t.ctx, bob.UserId); err != nil {
+		t.errorf("DeleteUser: %v", err)
+		return
+	}
+	if err := t.store.DeleteUser(t.ctx, bob.UserId); !errors.Is(err, types.ErrUserNotFound) {
+		t.errorf("DeleteUser of a deleted user = %v, want ErrUserNotFound", err)
+	}
+	if err := t.store.DeleteUser(t.ctx, unknownUserId); !errors.Is(err, types.ErrUserNotFound) {
+		t.errorf("DeleteUser of unknown user = %v, want ErrUserNotFound", err)
+	}
+
+	if _, err := t.store.FindUserById(t.ctx, bob.UserId); !errors.Is(err, types.ErrUserNotFound) {
+		t.errorf("FindUserById of a deleted user = %v, want ErrUserNotFound", err)
+	}
+	if _, err := t.store.FindUserByEmail(t.ctx, bob.Email); !errors.Is(err, types.ErrUserNotFound) {
+		t.errorf("FindUserByEmail of a deleted user = %v, want ErrUserNotFound", err)
+	}
+	if _, err := t.store.AuthenticateUser(t.ctx, bob.Email); !errors.Is(err, types.ErrUserNotFound) {
+		t.errorf("AuthenticateUser of a deleted user = %v, want ErrUserNotFound", err)
+	}
+
+	listed, metadata, err := t.store.GetAllUsers(t.ctx, repositories.UserListFilter{PerPage: 10})
+	if err != nil || slices.Contains(usernames(listed), bob.Username) || metadata.TotalRecords != len(users)-1 {
+		t.errorf("GetAllUsers after delete = %v, %v, want bob_builder left out", usernames(listed), err)
+	}
+	if results, _, err := t.store.SearchUsers(t.ctx, repositories.UserListFilter{PerPage: 10}, "bob"); err != nil || len(results) != 0 {
+		t.errorf("SearchUsers(bob) after delete = %d results, %v, want none", len(results), err)
+	}
+	if suggestions, err := t.store.AutocompleteUsers(t.ctx, "bob", 10); err != nil || len(suggestions) != 0 {
+		t.errorf("AutocompleteUsers(bob) after delete = %v, %v, want none", suggestions, err)
+	}
+
+	// A deleted user keeps the email and username taken.
+	if exists, err := t.store.IsUserExists(t.ctx, bob.Email, "nobody"); err != nil || !exists {
+		t.errorf("IsUserExists of a deleted user = %v, %v, want true", exists, err)
+	}
+	again := repositories.UserCreateDbModel{Email: bob.Email, Username: "bob2", FirstName: "Bob", LastName: "Again", PasswordHash: "x"}
+	if err := t.store.CreateUser(t.ctx, &again); !errors.Is(err, types.ErrDuplicateUser) {
+		t.errorf("CreateUser with a deleted user's email = %v, want ErrDuplicateUser", err)
+	}
+}
+
+// takenField is the field a duplicate error names in its "field" extension.
+func takenField(err error) string {
+	var appError *types.AppError
+	if !errors.As(err, &appError) {
+		return ""
+	}
+	field, _ := appError.Extensions["field"].(string)
+	return field
+}
+
+func usernames(users []*repositories.UserResponseModel) []string {
+	names := make([]string, len(users))
+	for i, user := range users {
+		names[i] = user.Username
+	}
+	return names
+}
+
+func suggestionNames(suggestions []*repositories.UserSuggestionModel) []string {
+	names := make([]string, len(suggestions))
+	for i, suggestion := range suggestions {
+		names[i] = suggestion.Username
+	}
+	return names
+}
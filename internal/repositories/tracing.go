@@ -0,0 +1,33 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fiber-auth-api/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// startQuerySpan starts a client span for one repository statement, named
+// after the repository method that runs it, e.g. UserRepository.CreateUser.
+func startQuerySpan(ctx context.Context, system attribute.KeyValue, repository string, statement string) (context.Context, trace.Span) {
+	return tracing.Start(ctx, repository+"."+statement,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			system,
+			semconv.DBOperationName(statement),
+		),
+	)
+}
+
+// endQuerySpan records the row count and ends span. sql.ErrNoRows is an
+// expected outcome, not a span error.
+func endQuerySpan(span trace.Span, rows int, err error) {
+	span.SetAttributes(attribute.Int("db.response.returned_rows", rows))
+	if errors.Is(err, sql.ErrNoRows) {
+		err = nil
+	}
+	tracing.End(span, err)
+}
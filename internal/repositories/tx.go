@@ -0,0 +1,216 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fiber-auth-api/internal/types"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"sync/atomic"
+	"time"
+)
+
+// DBTX is what repositories run statements on: the *sql.DB, or the *sql.Tx
+// of a WithTx call.
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// maxTxAttempts bounds how often WithTx runs a transaction that keeps
+// failing to serialize.
+const maxTxAttempts = 3
+
+// Repos groups the repositories of one database. Within WithTx they share
+// a transaction, so their writes commit or roll back together.
+type Repos struct {
+	Users UserStore
+	// Audit is nil for databases without the audit table.
+	Audit *AuditRepository
+
+	db      DBTX
+	dialect dialect
+	log     *slog.Logger
+	bind    func(db DBTX) Repos
+}
+
+// NewRepos returns the Postgres repositories on db.
+func NewRepos(db *sql.DB, log *slog.Logger, userConfig UserRepositoryConfig, auditConfig AuditRepositoryConfig) Repos {
+	var bind func(db DBTX) Repos
+	bind = func(db DBTX) Repos {
+		return Repos{
+			Users:   NewUserRepository(db, log, userConfig),
+			Audit:   NewAuditRepository(db, log, auditConfig),
+			db:      db,
+			dialect: postgresDialect,
+			log:     log,
+			bind:    bind,
+		}
+	}
+	return bind(db)
+}
+
+// NewSqliteRepos returns the SQLite repositories on db.
+func NewSqliteRepos(db *sql.DB, log *slog.Logger, userConfig UserRepositoryConfig) Repos {
+	var bind func(db DBTX) Repos
+	bind = func(db DBTX) Repos {
+		return Repos{
+			Users:   NewSqliteUserRepository(db, log, userConfig),
+			db:      db,
+			dialect: sqliteDialect,
+			log:     log,
+			bind:    bind,
+		}
+	}
+	return bind(db)
+}
+
+// NewMemoryRepos returns an in-memory user store. It has no transactions:
+// WithTx runs fn directly and an error does not undo its writes.
+func NewMemoryRepos(log *slog.Logger, userConfig UserRepositoryConfig) Repos {
+	return Repos{Users: NewMemoryUserRepository(log, userConfig), log: log}
+}
+
+// WithTx runs fn with repositories bound to a transaction, committing when
+// fn returns nil and rolling back when it returns an error or panics. fn
+// must only use the repositories it is given; the others run outside the
+// transaction, and on SQLite would wait for its only connection.
+//
+// Postgres transactions are serializable. When one fails to serialize, or
+// deadlocks, WithTx runs fn again in a new transaction, up to
+// maxTxAttempts times, so fn must not have effects outside the database
+// that cannot be repeated. Called on the repositories of a transaction,
+// WithTx runs fn in a savepoint instead: an error rolls back only fn's
+// writes and is retried, if at all, by the outermost WithTx.
+//
+// Audit events cannot be appended in a WithTx transaction; see
+// WithAuditedTx.
+func (repos Repos) WithTx(ctx context.Context, fn func(tx Repos) error) error {
+	return repos.withTx(ctx, repos.dialect.txOptions, fn)
+}
+
+// WithAuditedTx is WithTx for changes recorded in the audit chain along
+// with their events. Its Postgres transactions are read committed: a
+// serializable one reads the chain head from a snapshot taken before it
+// waited for the chain lock, and would link its event to the head another
+// transaction has just replaced. Uniqueness is still enforced by the
+// constraints, but fn must not rely on serializable reads otherwise.
+func (repos Repos) WithAuditedTx(ctx context.Context, fn func(tx Repos) error) error {
+	return repos.withTx(ctx, repos.dialect.auditedTxOptions, fn)
+}
+
+func (repos Repos) withTx(ctx context.Context, opts *sql.TxOptions, fn func(tx Repos) error) error {
+	if repos.db == nil {
+		return fn(repos)
+	}
+	if _, nested := repos.db.(*sql.Tx); nested {
+		return repos.runTx(ctx, opts, fn)
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := repos.runTx(ctx, opts, fn)
+		if err == nil || attempt == maxTxAttempts || !errors.Is(repos.dialect.classify(err), types.ErrSerializationFailure) {
+			return err
+		}
+		repos.log.WarnContext(ctx, "Retrying transaction after serialization failure", "attempt", attempt, "error", err)
+
+		// A growing, jittered delay lets the conflicting transaction finish.
+		delay := time.Duration(attempt)*10*time.Millisecond + rand.N(10*time.Millisecond)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
+}
+
+func (repos Repos) runTx(ctx context.Context, opts *sql.TxOptions, fn func(tx Repos) error) (err error) {
+	tx, err := beginNested(ctx, repos.db, opts)
+	if err != nil {
+		return repos.dialect.unavailable(err)
+	}
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			tx.rollback(ctx)
+			panic(recovered)
+		}
+		if err != nil {
+			if rollbackErr := tx.rollback(ctx); rollbackErr != nil {
+				repos.log.ErrorContext(ctx, "Failed to roll back transaction", "error", rollbackErr)
+			}
+		}
+	}()
+
+	if err = fn(repos.bind(tx.tx)); err != nil {
+		return err
+	}
+	if err = tx.commit(ctx); err != nil {
+		return repos.dialect.unavailable(fmt.Errorf("failed to commit transaction: %w", err))
+	}
+	return nil
+}
+
+var savepointSeq atomic.Int64
+
+// nestedTx is a transaction, or a savepoint when it was begun inside one.
+type nestedTx struct {
+	tx        *sql.Tx
+	savepoint string
+	done      bool
+}
+
+// beginNested begins a transaction on db, or a savepoint when db already is
+// a transaction, so code that needs atomicity works inside and outside
+// WithTx.
+func beginNested(ctx context.Context, db DBTX, opts *sql.TxOptions) (*nestedTx, error) {
+	switch db := db.(type) {
+	case *sql.DB:
+		tx, err := db.BeginTx(ctx, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		return &nestedTx{tx: tx}, nil
+	case *sql.Tx:
+		savepoint := fmt.Sprintf("sp_%d", savepointSeq.Add(1))
+		if _, err := db.ExecContext(ctx, "SAVEPOINT "+savepoint); err != nil {
+			return nil, fmt.Errorf("failed to create savepoint: %w", err)
+		}
+		return &nestedTx{tx: db, savepoint: savepoint}, nil
+	}
+	return nil, fmt.Errorf("cannot begin a transaction on %T", db)
+}
+
+func (nested *nestedTx) commit(ctx context.Context) error {
+	if nested.savepoint == "" {
+		nested.done = true
+		return nested.tx.Commit()
+	}
+	if _, err := nested.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+nested.savepoint); err != nil {
+		return err
+	}
+	nested.done = true
+	return nil
+}
+
+// rollback undoes the writes since begin. It does nothing after commit, so
+// it can be deferred.
+func (nested *nestedTx) rollback(ctx context.Context) error {
+	if nested.done {
+		return nil
+	}
+	nested.done = true
+	if nested.savepoint == "" {
+		return nested.tx.Rollback()
+	}
+	// The enclosing transaction carries on, even when ctx has ended the
+	// statement that failed.
+	ctx = context.WithoutCancel(ctx)
+	if _, err := nested.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+nested.savepoint); err != nil {
+		return err
+	}
+	_, err := nested.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+nested.savepoint)
+	return err
+}
@@ -0,0 +1,204 @@
+package repositories_test
+
+import (
+	"context"
+	"errors"
+	"fiber-auth-api/internal/repositories"
+	"fiber-auth-api/internal/types"
+	"fmt"
+	"testing"
+)
+
+// serializationFailure is what a conflicting transaction fails with once
+// classified.
+var serializationFailure = &types.DatabaseError{Kind: types.ErrSerializationFailure, Code: "40001"}
+
+func TestWithTx(t *testing.T) {
+	errFailed := errors.New("failed")
+	tests := []struct {
+		name string
+		// run creates users through tx and returns the error WithTx gets.
+		run       func(tx repositories.Repos, create func(tx repositories.Repos, name string) error) error
+		wantErr   error
+		wantUsers []string
+	}{
+		{
+			name: "commits",
+			run: func(tx repositories.Repos, create func(repositories.Repos, string) error) error {
+				return create(tx, "alice")
+			},
+			wantUsers: []string{"alice"},
+		},
+		{
+			name: "rolls back on error",
+			run: func(tx repositories.Repos, create func(repositories.Repos, string) error) error {
+				if err := create(tx, "alice"); err != nil {
+					return err
+				}
+				return errFailed
+			},
+			wantErr: errFailed,
+		},
+		{
+			name: "rolls back a failed savepoint only",
+			run: func(tx repositories.Repos, create func(repositories.Repos, string) error) error {
+				if err := create(tx, "alice"); err != nil {
+					return err
+				}
+				err := tx.WithTx(context.Background(), func(nested repositories.Repos) error {
+					if err := create(nested, "bob"); err != nil {
+						return err
+					}
+					return errFailed
+				})
+				if !errors.Is(err, errFailed) {
+					return fmt.Errorf("nested WithTx = %v", err)
+				}
+				return create(tx, "carol")
+			},
+			wantUsers: []string{"alice", "carol"},
+		},
+		{
+			name: "keeps a released savepoint",
+			run: func(tx repositories.Repos, create func(repositories.Repos, string) error) error {
+				return tx.WithTx(context.Background(), func(nested repositories.Repos) error {
+					return create(nested, "bob")
+				})
+			},
+			wantUsers: []string{"bob"},
+		},
+		{
+			name: "rolls back savepoints with the transaction",
+			run: func(tx repositories.Repos, create func(repositories.Repos, string) error) error {
+				if err := tx.WithTx(context.Background(), func(nested repositories.Repos) error {
+					return create(nested, "bob")
+				}); err != nil {
+					return err
+				}
+				return errFailed
+			},
+			wantErr: errFailed,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			repos := repositories.NewSqliteRepos(openSqlite(t), testLogger, testUserConfig)
+			create := func(tx repositories.Repos, name string) error {
+				return tx.Users.CreateUser(ctx, &repositories.UserCreateDbModel{
+					Email: name + "@example.com", Username: name, FirstName: name, LastName: "Test", PasswordHash: "x",
+				})
+			}
+
+			err := repos.WithTx(ctx, func(tx repositories.Repos) error {
+				return test.run(tx, create)
+			})
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("WithTx = %v, want %v", err, test.wantErr)
+			}
+			assertUsers(t, repos, test.wantUsers)
+		})
+	}
+}
+
+func TestWithTxRollsBackOnPanic(t *testing.T) {
+	ctx := context.Background()
+	repos := repositories.NewSqliteRepos(openSqlite(t), testLogger, testUserConfig)
+
+	func() {
+		defer func() {
+			if recovered := recover(); recovered != "boom" {
+				t.Errorf("recovered %v, want the panic of fn", recovered)
+			}
+		}()
+		repos.WithTx(ctx, func(tx repositories.Repos) error {
+			tx.Users.CreateUser(ctx, &repositories.UserCreateDbModel{
+				Email: "alice@example.com", Username: "alice", FirstName: "Alice", LastName: "Test", PasswordHash: "x",
+			})
+			panic("boom")
+		})
+	}()
+	assertUsers(t, repos, nil)
+}
+
+func TestWithTxRetriesSerializationFailures(t *testing.T) {
+	errFailed := errors.New("failed")
+	tests := []struct {
+		name         string
+		failures     int
+		failWith     error
+		nested       bool
+		wantAttempts int
+		wantErr      error
+	}{
+		{"succeeds after retrying", 2, serializationFailure, false, 3, nil},
+		{"gives up after maxTxAttempts", 5, serializationFailure, false, 3, types.ErrSerializationFailure},
+		{"does not retry other errors", 5, errFailed, false, 1, errFailed},
+		{"leaves retries to the outermost transaction", 5, serializationFailure, true, 1, types.ErrSerializationFailure},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			repos := repositories.NewSqliteRepos(openSqlite(t), testLogger, testUserConfig)
+
+			attempts := 0
+			fn := func(tx repositories.Repos) error {
+				attempts++
+				if attempts <= test.failures {
+					return test.failWith
+				}
+				return nil
+			}
+			var err error
+			if test.nested {
+				outer := repos.WithTx(ctx, func(tx repositories.Repos) error {
+					err = tx.WithTx(ctx, fn)
+					return nil
+				})
+				if outer != nil {
+					t.Fatalf("outer WithTx: %v", outer)
+				}
+			} else {
+				err = repos.WithTx(ctx, fn)
+			}
+
+			if !errors.Is(err, test.wantErr) || (test.wantErr == nil && err != nil) {
+				t.Errorf("WithTx = %v, want %v", err, test.wantErr)
+			}
+			if attempts != test.wantAttempts {
+				t.Errorf("fn ran %d times, want %d", attempts, test.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestMemoryWithTxRunsDirectly(t *testing.T) {
+	repos := repositories.NewMemoryRepos(testLogger, testUserConfig)
+	errFailed := errors.New("failed")
+	err := repos.WithTx(context.Background(), func(tx repositories.Repos) error {
+		tx.Users.CreateUser(context.Background(), &repositories.UserCreateDbModel{
+			Email: "alice@example.com", Username: "alice", FirstName: "Alice", LastName: "Test", PasswordHash: "x",
+		})
+		return errFailed
+	})
+	if !errors.Is(err, errFailed) {
+		t.Fatalf("WithTx = %v, want %v", err, errFailed)
+	}
+	// Without transactions the write stays.
+	assertUsers(t, repos, []string{"alice"})
+}
+
+func assertUsers(t *testing.T, repos repositories.Repos, want []string) {
+	t.Helper()
+	users, _, err := repos.Users.GetAllUsers(context.Background(), repositories.UserListFilter{SortBy: "username", PerPage: 10})
+	if err != nil {
+		t.Fatalf("GetAllUsers: %v", err)
+	}
+	var got []string
+	for _, user := range users {
+		got = append(got, user.Username)
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("users = %v, want %v", got, want)
+	}
+}
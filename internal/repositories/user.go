@@ -3,36 +3,50 @@ package repositories
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fiber-auth-api/internal/types"
+	"fiber-auth-api/internal/validation"
 	"fmt"
 	"log/slog"
-	"strings"
+	"slices"
+	"sort"
 	"time"
 )
 
 type UserRepository struct {
-	DB *sql.DB
-	log *slog.Logger
+	DB      DBTX
+	log     *slog.Logger
+	config  UserRepositoryConfig
+	dialect dialect
 }
 
-func NewUserRepository(db *sql.DB, log *slog.Logger) *UserRepository {
+type UserRepositoryConfig struct {
+	QueryTimeout time.Duration
+	PageSize     int
+}
+
+func NewUserRepository(db DBTX, log *slog.Logger, config UserRepositoryConfig) *UserRepository {
 	return &UserRepository{
-		DB: db,
-		log: log,
+		DB:      db,
+		log:     log,
+		config:  config,
+		dialect: postgresDialect,
 	}
 }
 
+// bcrypt refuses passwords longer than 72 bytes, whatever their length in
+// characters.
 type UserSignupModel struct {
-	Email     string `json:"email"`
-	Password  string `json:"password"`
-	Username  string `json:"username"`
-	FirstName string `json:"first_name"`
-	LastName  string `json:"last_name"`
+	Email     string `json:"email" normalize:"email" validate:"required,email,max=255"`
+	Password  string `json:"password" validate:"required,min=8,maxbytes=72,nefield=email"`
+	Username  string `json:"username" normalize:"username" validate:"required,min=3,max=50,regexp=username,singlescript,notreserved"`
+	FirstName string `json:"first_name" normalize:"text" validate:"required,max=100"`
+	LastName  string `json:"last_name" normalize:"text" validate:"required,max=100"`
 }
 
 type UserSigninModel struct {
-	Email	  string `json:"email"`
-	Password  string `json:"password"`
+	Email    string `json:"email" normalize:"email" validate:"required"`
+	Password string `json:"password" validate:"required"`
 }
 
 type UserCreateDbModel struct {
@@ -51,62 +65,65 @@ type UserCreateDbModel struct {
 }
 
 type UserResponseModel struct {
-	UserId          string `json:"user_id"`
-	Email           string `json:"email"`
-	Username        string `json:"username"`
-	FirstName      string `json:"first_name"`
-	LastName       string `json:"last_name"`
-	IsActive        bool   `json:"is_active"`
-	IsEmailVerified bool   `json:"is_email_verified"`
+	UserId          string    `json:"user_id"`
+	Email           string    `json:"email"`
+	Username        string    `json:"username"`
+	FirstName       string    `json:"first_name"`
+	LastName        string    `json:"last_name"`
+	IsActive        bool      `json:"is_active"`
+	IsEmailVerified bool      `json:"is_email_verified"`
+	CreatedAt       time.Time `json:"created_at"`
 }
 
 type UserAuthenticateResponseModel struct {
-	UserId          string `json:"user_id"`
-	Email           string `json:"email"`
-	Username        string `json:"username"`
-	PasswordHash    string    `json:"password_hash"`
+	UserId       string `json:"user_id"`
+	Email        string `json:"email"`
+	Username     string `json:"username"`
+	PasswordHash string `json:"password_hash"`
 }
 
-type Metadata struct {
-	TotalRecords int `json:"total_records"`
-	TotalPages   int `json:"total_pages"`
-	CurrentPage  int `json:"current_page"`
-	PerPage      int `json:"per_page"`
-	HasNext      bool `json:"has_next"`
-	HasPrev      bool `json:"has_prev"`
-	TotalCurrentRecords int `json:"total_current_records"`
+// userSortColumns whitelists the columns a user listing may be sorted by;
+// sort keys from requests never reach the SQL text otherwise.
+var userSortColumns = map[string]string{
+	"created_at":    "created_at",
+	"updated_at":    "updated_at",
+	"last_login_at": "last_login_at",
+	"username":      "username",
+	"email":         "email",
+	"first_name":    "first_name",
+	"last_name":     "last_name",
 }
 
-func NewMetadata(totalRecords int) *Metadata {
-	if totalRecords == 0 {
-		return &Metadata{
-			TotalRecords: 0,
-			TotalPages:   0,
-			CurrentPage:  0,
-			PerPage:      0,
-			HasNext:      false,
-			HasPrev:      false,
-			TotalCurrentRecords: 0,
-		}
+// UserSortFields lists the sort keys UserListFilter accepts.
+func UserSortFields() []string {
+	fields := make([]string, 0, len(userSortColumns))
+	for field := range userSortColumns {
+		fields = append(fields, field)
 	}
+	sort.Strings(fields)
+	return fields
+}
 
-	return &Metadata{
-		TotalRecords: totalRecords,
-		TotalPages:   totalRecords / 5,
-		CurrentPage:  1,
-		PerPage:      5,
-		HasNext:      totalRecords > 5,
-		HasPrev:      false,
-		TotalCurrentRecords: 5,
-	}
+// UserListFilter narrows and orders GetAllUsers. Nil and zero fields match
+// everything; CreatedFrom is inclusive and CreatedTo exclusive.
+type UserListFilter struct {
+	Page            int
+	PerPage         int
+	SortBy          string
+	SortDesc        bool
+	IsActive        *bool
+	IsEmailVerified *bool
+	CreatedFrom     time.Time
+	CreatedTo       time.Time
 }
 
-// type UserListResponseModel struct {
-// 	users []*UserResponseModel
-// 	metadata Metadata
-// }
+// Offset is the number of rows before Page, clamped rather than overflowing
+// for pages far past the end.
+func (filter UserListFilter) Offset() int {
+	return pageOffset(filter.Page, filter.PerPage)
+}
 
-func (userRepo UserRepository) CreateUser(user *UserCreateDbModel) error {
+func (userRepo UserRepository) CreateUser(ctx context.Context, user *UserCreateDbModel) (err error) {
 	query := `
         INSERT INTO users (
             username, 
@@ -115,15 +132,24 @@ func (userRepo UserRepository) CreateUser(user *UserCreateDbModel) error {
             first_name, 
             last_name, 
             is_active, 
-            is_email_verified
+            is_email_verified,
+            username_skeleton
         ) 
-        VALUES ($1, $2, $3, $4, $5, $6, $7)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
         RETURNING user_id, created_at, updated_at`
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	// Handlers bind already normalized values; normalizing again here keeps
+	// every path into the table consistent with the lookups.
+	user.Email = validation.NormalizeEmail(user.Email)
+	user.Username = validation.NormalizeUsername(user.Username)
+
+	ctx, span := startQuerySpan(ctx, userRepo.dialect.system, "UserRepository", "CreateUser")
+	defer func() { endQuerySpan(span, rowCount(err), err) }()
+
+	ctx, cancel := context.WithTimeout(ctx, userRepo.config.QueryTimeout)
 	defer cancel()
 
-	err := userRepo.DB.QueryRowContext(
+	err = userRepo.DB.QueryRowContext(
 		ctx,
 		query,
 		user.Username,
@@ -133,24 +159,28 @@ func (userRepo UserRepository) CreateUser(user *UserCreateDbModel) error {
 		user.LastName,
 		user.IsActive,
 		user.IsEmailVerified,
+		validation.UsernameSkeleton(user.Username),
 	).Scan(&user.UserId, &user.CreatedAt, &user.UpdatedAt)
 
 	if err != nil {
-		if isDuplicateKeyError(err) {
-			userRepo.log.Error("User already exists", "error", err)
-			return types.ErrDuplicateUser
+		err = userRepo.apiError(err)
+		if errors.Is(err, types.ErrDuplicateUser) {
+			userRepo.log.ErrorContext(ctx, "User already exists", "error", err)
+			return err
 		}
-		userRepo.log.Error("Something went wrong creating user", "error", err)
+		userRepo.log.ErrorContext(ctx, "Something went wrong creating user", "error", err)
 		return fmt.Errorf("failed to create user: %w", err)
 	}
 	return nil
 }
 
-func (userRepo UserRepository) AuthenticateUser(email string) (*UserAuthenticateResponseModel, error) {
-	query := `SELECT user_id, email, username, password_hash FROM users WHERE email = $1`
+func (userRepo UserRepository) AuthenticateUser(ctx context.Context, email string) (*UserAuthenticateResponseModel, error) {
+	query := `SELECT user_id, email, username, password_hash FROM users WHERE lower(email) = lower($1) AND deleted_at IS NULL`
+	email = validation.NormalizeEmail(email)
 
+	ctx, span := startQuerySpan(ctx, userRepo.dialect.system, "UserRepository", "AuthenticateUser")
 	var user UserAuthenticateResponseModel
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, userRepo.config.QueryTimeout)
 	defer cancel()
 
 	err := userRepo.DB.QueryRowContext(ctx, query, email).Scan(
@@ -159,74 +189,197 @@ func (userRepo UserRepository) AuthenticateUser(email string) (*UserAuthenticate
 		&user.Username,
 		&user.PasswordHash,
 	)
+	endQuerySpan(span, rowCount(err), err)
 
 	if err != nil {
-		userRepo.log.Error("Failed to get user", "error", err)
-		return nil, types.ErrUserNotFound
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, types.ErrUserNotFound
+		}
+		userRepo.log.ErrorContext(ctx, "Failed to get user", "error", err)
+		return nil, userRepo.apiError(err)
 	}
 
 	return &user, nil
 }
 
-func (userRepo UserRepository) GetAllUsers() ([]*UserResponseModel, *Metadata, error) {
-	query := `
-		SELECT 
-			user_id, 
-			email, 
-			first_name, 
-			last_name, 
-			username, 
-			is_email_verified, 
-			is_active,
-			count(*) OVER() as total_count 
-		FROM users 
-		ORDER BY created_at DESC 
-		LIMIT $1 
-		OFFSET $2`
-
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (userRepo UserRepository) GetAllUsers(ctx context.Context, filter UserListFilter) (users []*UserResponseModel, metadata *types.Metadata, err error) {
+	ctx, span := startQuerySpan(ctx, userRepo.dialect.system, "UserRepository", "GetAllUsers")
+	defer func() { endQuerySpan(span, len(users), err) }()
+
+	if filter.PerPage <= 0 {
+		filter.PerPage = userRepo.config.PageSize
+	}
+	if filter.Page <= 0 {
+		filter.Page = 1
+	}
+	if filter.SortBy == "" {
+		filter.SortBy, filter.SortDesc = "created_at", true
+	}
+	column, ok := userSortColumns[filter.SortBy]
+	if !ok {
+		return nil, nil, fmt.Errorf("unsupported sort field %q", filter.SortBy)
+	}
+	direction := "ASC"
+	if filter.SortDesc {
+		direction = "DESC"
+	}
+	// Only last_login_at is nullable; leaving the other columns with the
+	// default null ordering keeps users_created_at_idx usable.
+	nulls := ""
+	if column == "last_login_at" {
+		nulls = " NULLS LAST"
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, userRepo.config.QueryTimeout)
 	defer cancel()
 
-	rows, err := userRepo.DB.QueryContext(ctx, query, 5, 0)
+	where := userListWhere(filter, userRepo.dialect)
+
+	var totalRecords int
+	if err = userRepo.DB.QueryRowContext(ctx, `SELECT count(*) FROM users `+where.String(), where.args...).Scan(&totalRecords); err != nil {
+		userRepo.log.ErrorContext(ctx, "Failed to count users", "error", err)
+		return nil, nil, userRepo.apiError(err)
+	}
+
+	// user_id breaks ties so rows with equal sort values keep a stable order
+	// across pages.
+	query := fmt.Sprintf(`
+		SELECT user_id, email, first_name, last_name, username, is_email_verified, is_active, created_at
+		FROM users %s
+		ORDER BY %s %s%s, user_id %s
+		LIMIT %s OFFSET %s`,
+		where.String(), column, direction, nulls, direction,
+		where.arg(filter.PerPage), where.arg(filter.Offset()))
+
+	rows, err := userRepo.DB.QueryContext(ctx, query, where.args...)
 	if err != nil {
-		userRepo.log.Error("Failed to get all users", "error", err)
-		return nil, &Metadata{}, err
+		userRepo.log.ErrorContext(ctx, "Failed to get all users", "error", err)
+		return nil, nil, userRepo.apiError(err)
 	}
 	defer rows.Close()
 
-	users := make([]*UserResponseModel, 0)
+	if users, err = scanUsers(rows); err != nil {
+		userRepo.log.ErrorContext(ctx, "Failed to scan users", "error", err)
+		return nil, nil, userRepo.apiError(err)
+	}
 
-	var totalRecords int
+	return users, types.NewMetadata(totalRecords, filter.Page, filter.PerPage, len(users)), nil
+}
+
+// UserKeyset is the position GetUsersByKeyset continues from: the
+// created_at and user_id of the last row the client has seen.
+type UserKeyset struct {
+	CreatedAt time.Time
+	UserId    string
+}
+
+// GetUsersByKeyset returns up to filter.PerPage users following after, or
+// preceding it when backward is set, in created_at order. Unlike the offset
+// mode it seeks through users_created_at_idx instead of counting and
+// skipping rows, and pages do not shift when users are added. A nil after
+// starts at the beginning of the listing. hasMore reports whether further
+// users exist in the direction read; users are always returned in listing
+// order. Page and SortBy are ignored.
+func (userRepo UserRepository) GetUsersByKeyset(ctx context.Context, filter UserListFilter, after *UserKeyset, backward bool) (users []*UserResponseModel, hasMore bool, err error) {
+	ctx, span := startQuerySpan(ctx, userRepo.dialect.system, "UserRepository", "GetUsersByKeyset")
+	defer func() { endQuerySpan(span, len(users), err) }()
+
+	if filter.PerPage <= 0 {
+		filter.PerPage = userRepo.config.PageSize
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, userRepo.config.QueryTimeout)
+	defer cancel()
+
+	// Reading backward walks the index the other way and the rows are
+	// reversed afterwards.
+	descending := filter.SortDesc != backward
+	direction, comparison := "ASC", ">"
+	if descending {
+		direction, comparison = "DESC", "<"
+	}
+
+	where := userListWhere(filter, userRepo.dialect)
+	if after != nil {
+		where.add("(created_at, user_id) "+comparison+" (?, ?)", userRepo.dialect.timeArg(after.CreatedAt), after.UserId)
+	}
+
+	// One extra row tells whether another page follows.
+	query := fmt.Sprintf(`
+		SELECT user_id, email, first_name, last_name, username, is_email_verified, is_active, created_at
+		FROM users %s
+		ORDER BY created_at %s, user_id %s
+		LIMIT %s`,
+		where.String(), direction, direction, where.arg(filter.PerPage+1))
+
+	rows, err := userRepo.DB.QueryContext(ctx, query, where.args...)
+	if err != nil {
+		userRepo.log.ErrorContext(ctx, "Failed to get users by keyset", "error", err)
+		return nil, false, userRepo.apiError(err)
+	}
+	defer rows.Close()
+
+	if users, err = scanUsers(rows); err != nil {
+		userRepo.log.ErrorContext(ctx, "Failed to scan users", "error", err)
+		return nil, false, userRepo.apiError(err)
+	}
+
+	if hasMore = len(users) > filter.PerPage; hasMore {
+		users = users[:filter.PerPage]
+	}
+	if backward {
+		slices.Reverse(users)
+	}
+	return users, hasMore, nil
+}
+
+func userListWhere(filter UserListFilter, dialect dialect) *whereClause {
+	where := &whereClause{}
+	where.add("deleted_at IS NULL")
+	if filter.IsActive != nil {
+		where.add("is_active = ?", *filter.IsActive)
+	}
+	if filter.IsEmailVerified != nil {
+		where.add("is_email_verified = ?", *filter.IsEmailVerified)
+	}
+	if !filter.CreatedFrom.IsZero() {
+		where.add("created_at >= ?", dialect.timeArg(filter.CreatedFrom))
+	}
+	if !filter.CreatedTo.IsZero() {
+		where.add("created_at < ?", dialect.timeArg(filter.CreatedTo))
+	}
+	return where
+}
+
+func scanUsers(rows *sql.Rows) ([]*UserResponseModel, error) {
+	users := make([]*UserResponseModel, 0)
 	for rows.Next() {
 		user := &UserResponseModel{}
 		err := rows.Scan(
 			&user.UserId,
 			&user.Email,
-			&user.Username,
 			&user.FirstName,
 			&user.LastName,
-			&user.IsActive,
+			&user.Username,
 			&user.IsEmailVerified,
-			&totalRecords,
+			&user.IsActive,
+			&user.CreatedAt,
 		)
 		if err != nil {
-			userRepo.log.Error("Failed to scan user", "error", err)
-			return nil, &Metadata{}, err
+			return nil, err
 		}
 		users = append(users, user)
 	}
-
-	metadata := NewMetadata(totalRecords)
-
-	return users, metadata, nil
+	return users, rows.Err()
 }
 
-func (userRepo UserRepository) FindUserById(userId string) (*UserResponseModel, error) {
+func (userRepo UserRepository) FindUserById(ctx context.Context, userId string) (*UserResponseModel, error) {
 
-	query := `SELECT user_id, email, first_name, last_name, username, is_email_verified, is_active FROM users WHERE user_id = $1`
+	query := `SELECT user_id, email, first_name, last_name, username, is_email_verified, is_active, created_at FROM users WHERE user_id = $1 AND deleted_at IS NULL`
 
+	ctx, span := startQuerySpan(ctx, userRepo.dialect.system, "UserRepository", "FindUserById")
 	var user UserResponseModel
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, userRepo.config.QueryTimeout)
 	defer cancel()
 
 	err := userRepo.DB.QueryRowContext(ctx, query, userId).Scan(
@@ -237,11 +390,17 @@ func (userRepo UserRepository) FindUserById(userId string) (*UserResponseModel,
 		&user.Username,
 		&user.IsEmailVerified,
 		&user.IsActive,
+		&user.CreatedAt,
 	)
+	endQuerySpan(span, rowCount(err), err)
 
 	if err != nil {
-		userRepo.log.Error("Failed to get user by id", "error", err)
-		return nil, err
+		// No user has an id Postgres cannot even parse as a uuid.
+		if errors.Is(err, sql.ErrNoRows) || errors.Is(userRepo.dialect.classify(err), types.ErrInvalidData) {
+			return nil, types.ErrUserNotFound
+		}
+		userRepo.log.ErrorContext(ctx, "Failed to get user by id", "error", err)
+		return nil, userRepo.apiError(err)
 	}
 
 	return &user, nil
@@ -252,13 +411,15 @@ func (userRepo UserRepository) FindUserByUsername(username string) {
 
 }
 
-func (userRepo UserRepository) FindUserByEmail(email string) (*UserResponseModel, error) {
-	query := `SELECT user_id, email, first_name, last_name, username, is_email_verified, is_active FROM users WHERE email = $1`
+func (userRepo UserRepository) FindUserByEmail(ctx context.Context, email string) (*UserResponseModel, error) {
+	query := `SELECT user_id, email, first_name, last_name, username, is_email_verified, is_active, created_at FROM users WHERE lower(email) = lower($1) AND deleted_at IS NULL`
+	email = validation.NormalizeEmail(email)
 
+	ctx, span := startQuerySpan(ctx, userRepo.dialect.system, "UserRepository", "FindUserByEmail")
 	var user UserResponseModel
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, userRepo.config.QueryTimeout)
 	defer cancel()
-	
+
 	err := userRepo.DB.QueryRowContext(ctx, query, email).Scan(
 		&user.UserId,
 		&user.Email,
@@ -267,11 +428,16 @@ func (userRepo UserRepository) FindUserByEmail(email string) (*UserResponseModel
 		&user.Username,
 		&user.IsEmailVerified,
 		&user.IsActive,
+		&user.CreatedAt,
 	)
+	endQuerySpan(span, rowCount(err), err)
 
 	if err != nil {
-		userRepo.log.Error("Failed to get user by email", "error", err)
-		return nil, types.ErrUserNotFound
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, types.ErrUserNotFound
+		}
+		userRepo.log.ErrorContext(ctx, "Failed to get user by email", "error", err)
+		return nil, userRepo.apiError(err)
 	}
 
 	return &user, nil
@@ -283,21 +449,91 @@ func (userRepo UserRepository) UpdateUserById(userId int) {}
 
 func (userRepo UserRepository) UpdateUserPasswordById(userId int) {}
 
-func (userRepo UserRepository) DeleteUser() {}
+// DeleteUser soft-deletes a user: the row stays, keeping its email and
+// username taken, but lookups and listings no longer return it.
+func (userRepo UserRepository) DeleteUser(ctx context.Context, userId string) (err error) {
+	query := `UPDATE users SET deleted_at = ` + userRepo.dialect.now + ` WHERE user_id = $1 AND deleted_at IS NULL`
+
+	ctx, span := startQuerySpan(ctx, userRepo.dialect.system, "UserRepository", "DeleteUser")
+	var deleted int64
+	defer func() { endQuerySpan(span, int(deleted), err) }()
+
+	ctx, cancel := context.WithTimeout(ctx, userRepo.config.QueryTimeout)
+	defer cancel()
+
+	result, err := userRepo.DB.ExecContext(ctx, query, userId)
+	if err == nil {
+		deleted, err = result.RowsAffected()
+	}
+	if err != nil {
+		userRepo.log.ErrorContext(ctx, "Failed to delete user", "error", err)
+		return userRepo.apiError(fmt.Errorf("failed to delete user: %w", err))
+	}
+	if deleted == 0 {
+		return types.ErrUserNotFound
+	}
+	return nil
+}
 
-func (userRepo UserRepository) IsUserExists(email string, username string) (bool, error) {
+func (userRepo UserRepository) IsUserExists(ctx context.Context, email string, username string) (bool, error) {
 
-	query := `SELECT EXISTS(SELECT 1 FROM users WHERE email = $1 OR username = $2)`
+	query := `SELECT EXISTS(SELECT 1 FROM users WHERE lower(email) = lower($1) OR lower(username) = lower($2) OR username_skeleton = $3)`
+	email = validation.NormalizeEmail(email)
+	username = validation.NormalizeUsername(username)
+
+	ctx, span := startQuerySpan(ctx, userRepo.dialect.system, "UserRepository", "IsUserExists")
+	ctx, cancel := context.WithTimeout(ctx, userRepo.config.QueryTimeout)
+	defer cancel()
 
 	userExists := false
-	err := userRepo.DB.QueryRowContext(context.Background(), query, email, username).Scan(&userExists)
+	err := userRepo.DB.QueryRowContext(ctx, query, email, username, validation.UsernameSkeleton(username)).Scan(&userExists)
+	endQuerySpan(span, rowCount(err), err)
 	if err != nil {
-		userRepo.log.Error("Failed to check if user exists", "error", err)
-		return false, err
+		userRepo.log.ErrorContext(ctx, "Failed to check if user exists", "error", err)
+		return false, userRepo.apiError(err)
 	}
 	return userExists, nil
 }
 
-func isDuplicateKeyError(err error) bool {
-    return strings.Contains(err.Error(), "duplicate key value violates unique constraint")
-}
\ No newline at end of file
+// uniqueUserFields names the field each unique constraint on users guards,
+// in the Postgres and SQLite schemas. SQLite reports a unique index on a
+// plain column by the column, which is listed too.
+var uniqueUserFields = map[string]string{
+	"users_email_key":             "email",
+	"users_email_lower_key":       "email",
+	"users_username_key":          "username",
+	"users_username_lower_key":    "username",
+	"users_username_skeleton_key": "username",
+	"username_skeleton":           "username",
+}
+
+// apiError classifies err and reports it as the handlers return it: a taken
+// email or username as ErrEmailTaken or ErrUsernameTaken, and what
+// dialect.unavailable reports. Other errors are returned as they are.
+func (userRepo UserRepository) apiError(err error) error {
+	err = userRepo.dialect.unavailable(err)
+	var databaseError *types.DatabaseError
+	if !errors.As(err, &databaseError) || databaseError.Kind != types.ErrUniqueViolation {
+		return err
+	}
+	key := databaseError.Constraint
+	if key == "" {
+		key = databaseError.Column
+	}
+	switch uniqueUserFields[key] {
+	case "email":
+		return types.ErrEmailTaken.WithCause(err)
+	case "username":
+		return types.ErrUsernameTaken.WithCause(err)
+	}
+	return types.ErrDuplicateUser.WithCause(err)
+}
+
+// rowCount is the number of rows a single-row query returned given its
+// Scan error.
+func rowCount(err error) int {
+	if err != nil {
+		return 0
+	}
+	return 1
+}
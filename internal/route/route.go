@@ -1,15 +1,79 @@
 package route
 
 import (
+	"context"
+	"fiber-auth-api/internal/audit"
+	"fiber-auth-api/internal/database"
 	"fiber-auth-api/internal/handlers"
+	"fiber-auth-api/internal/health"
+	"fiber-auth-api/internal/helper"
+	"fiber-auth-api/internal/i18n"
+	"fiber-auth-api/internal/logger"
+	"fiber-auth-api/internal/metrics"
+	"fiber-auth-api/internal/middleware"
+	"fiber-auth-api/internal/migrations"
 	"fiber-auth-api/internal/models"
 	"fiber-auth-api/internal/repositories"
+	"fiber-auth-api/internal/tracing"
+	"fmt"
 )
 
-func SetupRoutes(app models.Application) {
-	userRepository := repositories.NewUserRepository(app.PsqlDb, app.SlogLogger)
-	dbModel := models.NewDbModel(userRepository)
-	userHandler := handlers.NewUserHandler(app, dbModel)
+func SetupRoutes(app models.Application) error {
+	app.FiberApp.Use(middleware.RequestID())
+
+	bundle, err := i18n.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load message catalogs: %w", err)
+	}
+	app.FiberApp.Use(i18n.Middleware(bundle))
+
+	if app.Config.Log.Access.Enabled {
+		accessLogger, err := logger.NewAccessLogger(logger.AccessLogConfig{
+			Format:            app.Config.Log.Access.Format,
+			SuccessSampleRate: app.Config.Log.Access.SampleRate,
+		})
+		if err != nil {
+			return err
+		}
+		app.FiberApp.Use(middleware.AccessLog(accessLogger))
+	}
+
+	app.FiberApp.Use(tracing.Middleware())
+
+	if app.Config.Metrics.Enabled {
+		app.FiberApp.Use(metrics.Middleware())
+		app.FiberApp.Get(app.Config.Metrics.Path, metrics.Handler())
+	}
+
+	repos, err := newRepos(app)
+	if err != nil {
+		return err
+	}
+	identifierSecret := app.Config.Audit.IdentifierSecret
+	if identifierSecret == "" {
+		identifierSecret = app.Config.Auth.JWTSecret
+	}
+	auditLogger := audit.NewAuditLogger(repos.Audit, logger.ForPackage("audit"), identifierSecret)
+	dbModel := models.NewDbModel(repos)
+	userHandler := handlers.NewUserHandler(app, dbModel, auditLogger)
+	healthHandler := handlers.NewHealthHandler(app, readinessChecks(app))
+
+	app.FiberApp.Get("/healthz", healthHandler.LivenessHandler)
+	app.FiberApp.Get("/livez", healthHandler.LivenessHandler)
+	app.FiberApp.Get("/readyz", healthHandler.ReadinessHandler)
+
+	if app.Config.Admin.Token != "" {
+		adminHandler := handlers.NewAdminHandler(app, auditLogger)
+		auditHandler := handlers.NewAuditHandler(app, auditLogger)
+		admin := app.FiberApp.Group("/admin", middleware.AdminAuth(app.Config.Admin.Token))
+		admin.Get("/log-level", adminHandler.GetLogLevelsHandler)
+		admin.Put("/log-level", adminHandler.UpdateLogLevelsHandler)
+		if repos.Audit != nil {
+			admin.Get("/audit-events", auditHandler.ListAuditEventsHandler)
+			admin.Get("/audit-events/verify", auditHandler.VerifyAuditChainHandler)
+		}
+		admin.Get("/users/autocomplete", userHandler.AutocompleteUsersHandler)
+	}
 
 	apiV1 := app.FiberApp.Group("/api/v1")
 	apiV1.Post("/signup", userHandler.SignUpHandler)
@@ -19,4 +83,71 @@ func SetupRoutes(app models.Application) {
 	apiV1.Get("/:id", userHandler.GetUserByIdHandler)
 	apiV1.Get("/:username/", userHandler.GetUserByUsernameHandler)
 	apiV1.Get("/:email/", userHandler.GetUserByEmailHandler)
+
+	return nil
+}
+
+// newRepos builds the repositories for the configured database driver.
+// Their audit repository is nil without Postgres, which disables the audit
+// log.
+func newRepos(app models.Application) (repositories.Repos, error) {
+	userConfig := repositories.UserRepositoryConfig{
+		QueryTimeout: app.Config.Database.QueryTimeout,
+		PageSize:     app.Config.Pagination.DefaultPageSize,
+	}
+
+	switch app.Config.Database.Driver {
+	case database.DriverPostgres:
+		return repositories.NewRepos(app.PsqlDb, logger.ForPackage("repositories"), userConfig, repositories.AuditRepositoryConfig{
+			QueryTimeout: app.Config.Database.QueryTimeout,
+		}), nil
+	case database.DriverSqlite:
+		return repositories.NewSqliteRepos(app.SqliteDb, logger.ForPackage("repositories"), userConfig), nil
+	case database.DriverMemory:
+		return repositories.NewMemoryRepos(logger.ForPackage("repositories"), userConfig), nil
+	}
+	return repositories.Repos{}, fmt.Errorf("unsupported database driver %q", app.Config.Database.Driver)
+}
+
+func readinessChecks(app models.Application) *health.Registry {
+	registry := health.NewRegistry(app.Config.Health.CheckTimeout)
+
+	registry.Register("lifecycle", func(ctx context.Context) error {
+		if app.Lifecycle.IsDraining() {
+			return fmt.Errorf("draining connections for shutdown")
+		}
+		return nil
+	})
+
+	// The memory driver has no database to ping or migrate.
+	db, newMigrator := app.PsqlDb, migrations.NewMigrator
+	if app.SqliteDb != nil {
+		db, newMigrator = app.SqliteDb, migrations.NewSqliteMigrator
+	}
+	if db != nil {
+		registry.Register("database", func(ctx context.Context) error {
+			return db.PingContext(ctx)
+		})
+
+		migrator, err := newMigrator(db, logger.ForPackage("migrations"))
+		registry.Register("migrations", func(ctx context.Context) error {
+			if err != nil {
+				return err
+			}
+			pending, err := migrator.Pending(ctx)
+			if err != nil {
+				return err
+			}
+			if len(pending) > 0 {
+				return fmt.Errorf("%d pending migration(s), latest %06d_%s", len(pending), pending[len(pending)-1].Version, pending[len(pending)-1].Name)
+			}
+			return nil
+		})
+	}
+
+	registry.Register("token_keys", func(ctx context.Context) error {
+		return helper.CheckTokenKey()
+	})
+
+	return registry
 }
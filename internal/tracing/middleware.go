@@ -0,0 +1,77 @@
+package tracing
+
+import (
+	"fiber-auth-api/internal/types"
+	"github.com/gofiber/fiber/v3"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// headerCarrier reads propagation headers from the request and writes them
+// to the response.
+type headerCarrier struct {
+	c fiber.Ctx
+}
+
+func (carrier headerCarrier) Get(key string) string {
+	return carrier.c.Get(key)
+}
+
+func (carrier headerCarrier) Set(key string, value string) {
+	carrier.c.Set(key, value)
+}
+
+func (carrier headerCarrier) Keys() []string {
+	keys := make([]string, 0)
+	for key := range carrier.c.GetReqHeaders() {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+var _ propagation.TextMapCarrier = headerCarrier{}
+
+// Middleware starts the root server span for each request, continuing any
+// trace given in an incoming traceparent header, and stores it in the
+// request's user context for handlers and repositories.
+func Middleware() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		ctx := otel.GetTextMapPropagator().Extract(c.UserContext(), headerCarrier{c: c})
+
+		ctx, span := Start(ctx, "HTTP "+c.Method(),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(c.Method()),
+				semconv.URLPath(c.Path()),
+				semconv.ClientAddress(c.IP()),
+				semconv.UserAgentOriginal(c.Get(fiber.HeaderUserAgent)),
+			),
+		)
+		defer span.End()
+
+		c.SetUserContext(ctx)
+		middlewareRoute := c.Route()
+
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+		if err != nil {
+			status = types.StatusCode(err)
+			span.RecordError(err)
+		}
+
+		if route := c.Route(); route != middlewareRoute {
+			span.SetName(c.Method() + " " + route.Path)
+			span.SetAttributes(semconv.HTTPRoute(route.Path))
+		}
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if status >= fiber.StatusInternalServerError {
+			span.SetStatus(codes.Error, "")
+		}
+
+		return err
+	}
+}
@@ -0,0 +1,108 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSetupRejectsUnknownExporter(t *testing.T) {
+	if _, err := Setup(context.Background(), Options{ServiceName: "test", Exporter: "zipkin"}); err == nil {
+		t.Error("Setup accepted an unknown exporter")
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	app := fiber.New()
+	app.Use(Middleware())
+	app.Get("/users/:id", func(c fiber.Ctx) error {
+		// Spans started by handlers and repositories are children of
+		// the request span.
+		_, span := Start(c.UserContext(), "UserRepository.GetUserById")
+		span.End()
+		return c.SendString("ok")
+	})
+	app.Get("/broken", func(c fiber.Ctx) error {
+		return errors.New("boom")
+	})
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	tests := []struct {
+		name        string
+		path        string
+		traceparent string
+		wantName    string
+		wantRoute   string
+		wantStatus  int
+		wantError   bool
+	}{
+		{"continues the incoming trace", "/users/7", "00-" + traceID + "-00f067aa0ba902b7-01", "GET /users/:id", "/users/:id", 200, false},
+		{"failures mark the span", "/broken", "", "GET /broken", "/broken", 500, true},
+		{"unmatched paths keep the method name", "/nope", "", "HTTP GET", "", 404, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := tracetest.NewSpanRecorder()
+			otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+			req := httptest.NewRequest(fiber.MethodGet, test.path, nil)
+			if test.traceparent != "" {
+				req.Header.Set("traceparent", test.traceparent)
+			}
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("GET %s: %v", test.path, err)
+			}
+			resp.Body.Close()
+
+			var root sdktrace.ReadOnlySpan
+			for _, span := range recorder.Ended() {
+				if span.SpanKind() == trace.SpanKindServer {
+					root = span
+				} else if span.Parent().SpanID() == (trace.SpanID{}) {
+					t.Errorf("span %s has no parent", span.Name())
+				}
+			}
+			if root == nil {
+				t.Fatal("no server span was recorded")
+			}
+
+			if root.Name() != test.wantName {
+				t.Errorf("span name = %q, want %q", root.Name(), test.wantName)
+			}
+			if test.traceparent != "" && root.SpanContext().TraceID().String() != traceID {
+				t.Errorf("trace id = %s, want %s", root.SpanContext().TraceID(), traceID)
+			}
+			attributes := make(map[string]any)
+			for _, attribute := range root.Attributes() {
+				attributes[string(attribute.Key)] = attribute.Value.AsInterface()
+			}
+			if got := attributes[string(semconv.HTTPResponseStatusCodeKey)]; got != int64(test.wantStatus) {
+				t.Errorf("status attribute = %v, want %d", got, test.wantStatus)
+			}
+			if got, _ := attributes[string(semconv.HTTPRouteKey)].(string); got != test.wantRoute {
+				t.Errorf("route attribute = %q, want %q", got, test.wantRoute)
+			}
+			if (root.Status().Code == codes.Error) != test.wantError {
+				t.Errorf("span status = %v, want error %v", root.Status(), test.wantError)
+			}
+		})
+	}
+}
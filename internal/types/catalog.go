@@ -0,0 +1,76 @@
+package types
+
+import "net/http"
+
+// Error codes are part of the API: clients branch on them, so existing codes
+// must never change meaning.
+const (
+	CodeInvalidRequest     = "request.invalid"
+	CodeUnknownFields      = "request.unknown_fields"
+	CodeValidationFailed   = "request.validation_failed"
+	CodeInvalidQuery       = "request.invalid_query"
+	CodeMethodNotAllowed   = "request.method_not_allowed"
+	CodeRouteNotFound      = "request.route_not_found"
+	CodeRequestCanceled    = "request.canceled"
+	CodeUnauthorized       = "auth.unauthorized"
+	CodeInvalidCredentials = "auth.invalid_credentials"
+	CodeUserNotFound       = "user.not_found"
+	CodeDuplicateUser      = "user.duplicate"
+	CodeServiceUnavailable = "service.unavailable"
+	CodeInternal           = "internal.error"
+)
+
+// StatusClientClosedRequest is the nginx convention for a request the
+// client gave up on; the response is never read, but logs and metrics tell
+// it apart from server faults.
+const StatusClientClosedRequest = 499
+
+type ErrorDefinition struct {
+	Code   string
+	Status int
+	Title  string
+}
+
+var errorCatalog = map[string]ErrorDefinition{
+	CodeInvalidRequest:     {CodeInvalidRequest, http.StatusBadRequest, "Invalid request format"},
+	CodeUnknownFields:      {CodeUnknownFields, http.StatusBadRequest, "Invalid field names in request"},
+	CodeValidationFailed:   {CodeValidationFailed, http.StatusBadRequest, "Validation failed"},
+	CodeInvalidQuery:       {CodeInvalidQuery, http.StatusBadRequest, "Invalid query parameters"},
+	CodeMethodNotAllowed:   {CodeMethodNotAllowed, http.StatusMethodNotAllowed, "Method not allowed"},
+	CodeRouteNotFound:      {CodeRouteNotFound, http.StatusNotFound, "Resource could not be found"},
+	CodeRequestCanceled:    {CodeRequestCanceled, StatusClientClosedRequest, "Client closed request"},
+	CodeUnauthorized:       {CodeUnauthorized, http.StatusUnauthorized, "Unauthorized access"},
+	CodeInvalidCredentials: {CodeInvalidCredentials, http.StatusUnauthorized, "Invalid email or password"},
+	CodeUserNotFound:       {CodeUserNotFound, http.StatusNotFound, "User not found"},
+	CodeDuplicateUser:      {CodeDuplicateUser, http.StatusConflict, "User already exists"},
+	CodeServiceUnavailable: {CodeServiceUnavailable, http.StatusServiceUnavailable, "Service temporarily unavailable"},
+	CodeInternal:           {CodeInternal, http.StatusInternalServerError, "Internal server error"},
+}
+
+// LookupError returns the catalog entry for code; unknown codes are treated
+// as internal errors.
+func LookupError(code string) ErrorDefinition {
+	if definition, ok := errorCatalog[code]; ok {
+		return definition
+	}
+	return errorCatalog[CodeInternal]
+}
+
+// CodeForStatus picks the code for errors that only carry a status, such as
+// the ones Fiber raises itself.
+func CodeForStatus(status int) string {
+	switch status {
+	case http.StatusNotFound:
+		return CodeRouteNotFound
+	case http.StatusMethodNotAllowed:
+		return CodeMethodNotAllowed
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusServiceUnavailable:
+		return CodeServiceUnavailable
+	}
+	if status >= 400 && status < 500 {
+		return CodeInvalidRequest
+	}
+	return CodeInternal
+}
@@ -0,0 +1,50 @@
+package types
+
+import (
+	"errors"
+	"strings"
+)
+
+// Kinds of database failure. A *DatabaseError matches its kind with
+// errors.Is, whichever database raised it.
+var (
+	ErrUniqueViolation      = errors.New("unique violation")
+	ErrForeignKeyViolation  = errors.New("foreign key violation")
+	ErrNotNullViolation     = errors.New("not null violation")
+	ErrCheckViolation       = errors.New("check violation")
+	ErrInvalidData          = errors.New("invalid data")
+	ErrSerializationFailure = errors.New("serialization failure")
+	ErrQueryCanceled        = errors.New("query canceled")
+	ErrDatabaseUnavailable  = errors.New("database unavailable")
+)
+
+// DatabaseError is a failed statement classified by kind. Code is the
+// SQLSTATE, or the extended result code on SQLite; Constraint, Table and
+// Column are set when the database reports them. Err, the driver's error,
+// stays reachable through errors.As.
+type DatabaseError struct {
+	Kind       error
+	Code       string
+	Constraint string
+	Table      string
+	Column     string
+	Err        error
+}
+
+func (databaseError *DatabaseError) Error() string {
+	var message strings.Builder
+	message.WriteString(databaseError.Kind.Error())
+	if databaseError.Constraint != "" {
+		message.WriteString(" on " + databaseError.Constraint)
+	} else if databaseError.Column != "" {
+		message.WriteString(" on " + databaseError.Table + "." + databaseError.Column)
+	}
+	if databaseError.Err != nil {
+		message.WriteString(": " + databaseError.Err.Error())
+	}
+	return message.String()
+}
+
+func (databaseError *DatabaseError) Unwrap() []error {
+	return []error{databaseError.Kind, databaseError.Err}
+}
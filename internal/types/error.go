@@ -1,9 +1,120 @@
 package types
 
-import "fmt"
+import (
+	"errors"
+	"github.com/gofiber/fiber/v3"
+)
 
 var (
-	ErrInvalidInput  = fmt.Errorf("invalid input parameters")
-	ErrDuplicateUser = fmt.Errorf("user already exists")
-	ErrUserNotFound  = fmt.Errorf("user not found")
-)
\ No newline at end of file
+	ErrInvalidInput       = NewAppError(CodeInvalidRequest, "invalid input parameters").WithMessage("detail." + CodeInvalidRequest)
+	ErrDuplicateUser      = NewAppError(CodeDuplicateUser, "user already exists").WithMessage("detail." + CodeDuplicateUser)
+	ErrUserNotFound       = NewAppError(CodeUserNotFound, "user not found").WithMessage("detail." + CodeUserNotFound)
+	ErrInvalidCredentials = NewAppError(CodeInvalidCredentials, "invalid email or password").WithMessage("detail." + CodeInvalidCredentials)
+	ErrUnauthorized       = NewAppError(CodeUnauthorized, "unauthorized access").WithMessage("detail." + CodeUnauthorized)
+	ErrServiceUnavailable = NewAppError(CodeServiceUnavailable, "the service is temporarily unavailable").WithMessage("detail." + CodeServiceUnavailable)
+	ErrRequestCanceled    = NewAppError(CodeRequestCanceled, "the request was canceled before it completed").WithMessage("detail." + CodeRequestCanceled)
+	ErrInternal           = NewAppError(CodeInternal, "the server encountered a problem and could not process your request").WithMessage("detail." + CodeInternal)
+
+	// ErrEmailTaken and ErrUsernameTaken keep the code of ErrDuplicateUser,
+	// and match it, naming the taken field in a "field" extension.
+	ErrEmailTaken    = NewAppError(CodeDuplicateUser, "email already taken").WithMessage("detail.user.email_taken").WithExtension("field", "email")
+	ErrUsernameTaken = NewAppError(CodeDuplicateUser, "username already taken").WithMessage("detail.user.username_taken").WithExtension("field", "username")
+)
+
+// AppError is an error with a code from the error catalog. The ErrorHandler
+// renders it as a problem response; Cause is logged but never returned to
+// the client.
+type AppError struct {
+	Code       string
+	Detail     string
+	Cause      error
+	Extensions map[string]any
+	// Message, when set, replaces Detail in localized responses.
+	Message *Message
+}
+
+// Message is a translatable detail: a catalog key with its parameters. For
+// plural messages Count picks the plural form and is passed as {0}.
+type Message struct {
+	Key    string
+	Params []string
+	Count  int
+	Plural bool
+}
+
+func NewAppError(code string, detail string) *AppError {
+	return &AppError{Code: code, Detail: detail}
+}
+
+func (appError *AppError) Error() string {
+	if appError.Cause != nil {
+		return appError.Code + ": " + appError.Detail + ": " + appError.Cause.Error()
+	}
+	return appError.Code + ": " + appError.Detail
+}
+
+func (appError *AppError) Unwrap() error {
+	return appError.Cause
+}
+
+// Is matches any AppError with the same code, so errors.Is(err,
+// ErrDuplicateUser) holds for copies made with WithCause or WithExtension.
+func (appError *AppError) Is(target error) bool {
+	targetError, ok := target.(*AppError)
+	return ok && targetError.Code == appError.Code
+}
+
+func (appError *AppError) WithCause(cause error) *AppError {
+	clone := *appError
+	clone.Cause = cause
+	return &clone
+}
+
+// WithDetail replaces the detail and drops any message, which would no longer
+// match it.
+func (appError *AppError) WithDetail(detail string) *AppError {
+	clone := *appError
+	clone.Detail = detail
+	clone.Message = nil
+	return &clone
+}
+
+func (appError *AppError) WithMessage(key string, params ...string) *AppError {
+	clone := *appError
+	clone.Message = &Message{Key: key, Params: params}
+	return &clone
+}
+
+func (appError *AppError) WithPluralMessage(key string, count int, params ...string) *AppError {
+	clone := *appError
+	clone.Message = &Message{Key: key, Params: params, Count: count, Plural: true}
+	return &clone
+}
+
+// WithExtension adds a problem extension member such as "errors".
+func (appError *AppError) WithExtension(key string, value any) *AppError {
+	clone := *appError
+	clone.Extensions = make(map[string]any, len(appError.Extensions)+1)
+	for k, v := range appError.Extensions {
+		clone.Extensions[k] = v
+	}
+	clone.Extensions[key] = value
+	return &clone
+}
+
+func (appError *AppError) Status() int {
+	return LookupError(appError.Code).Status
+}
+
+// StatusCode is the HTTP status err will be rendered with.
+func StatusCode(err error) int {
+	var appError *AppError
+	if errors.As(err, &appError) {
+		return appError.Status()
+	}
+	var fiberError *fiber.Error
+	if errors.As(err, &fiberError) {
+		return fiberError.Code
+	}
+	return fiber.StatusInternalServerError
+}
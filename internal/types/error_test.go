@@ -0,0 +1,98 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func TestAppErrorMatching(t *testing.T) {
+	cause := errors.New("pq: duplicate key")
+	tests := []struct {
+		name   string
+		err    error
+		target error
+		want   bool
+	}{
+		{"same sentinel", ErrDuplicateUser, ErrDuplicateUser, true},
+		{"copy with a cause", ErrDuplicateUser.WithCause(cause), ErrDuplicateUser, true},
+		{"field specific copy", ErrEmailTaken, ErrDuplicateUser, true},
+		{"wrapped copy", fmt.Errorf("signup: %w", ErrUsernameTaken), ErrDuplicateUser, true},
+		{"different code", ErrUserNotFound, ErrDuplicateUser, false},
+		{"cause is reachable", ErrInternal.WithCause(cause), cause, true},
+	}
+	for _, test := range tests {
+		if got := errors.Is(test.err, test.target); got != test.want {
+			t.Errorf("%s: errors.Is = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestAppErrorCopiesLeaveSentinelsAlone(t *testing.T) {
+	err := ErrDuplicateUser.
+		WithCause(errors.New("cause")).
+		WithExtension("field", "email").
+		WithDetail("custom detail")
+
+	if ErrDuplicateUser.Cause != nil || ErrDuplicateUser.Extensions != nil || ErrDuplicateUser.Message == nil {
+		t.Errorf("ErrDuplicateUser was modified: %+v", ErrDuplicateUser)
+	}
+	if err.Message != nil || err.Detail != "custom detail" || err.Extensions["field"] != "email" {
+		t.Errorf("copy = %+v", err)
+	}
+	if got := err.Error(); got != "user.duplicate: custom detail: cause" {
+		t.Errorf("Error() = %q", got)
+	}
+
+	extended := ErrEmailTaken.WithExtension("errors", []string{"x"})
+	if _, ok := ErrEmailTaken.Extensions["errors"]; ok || extended.Extensions["field"] != "email" {
+		t.Errorf("WithExtension shared the extensions map: %v / %v", ErrEmailTaken.Extensions, extended.Extensions)
+	}
+}
+
+func TestStatusCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{ErrInvalidInput, http.StatusBadRequest},
+		{ErrInvalidCredentials, http.StatusUnauthorized},
+		{fmt.Errorf("wrapped: %w", ErrUserNotFound), http.StatusNotFound},
+		{ErrDuplicateUser, http.StatusConflict},
+		{ErrServiceUnavailable, http.StatusServiceUnavailable},
+		{ErrRequestCanceled, StatusClientClosedRequest},
+		{NewAppError("no.such_code", "unknown"), http.StatusInternalServerError},
+		{fiber.NewError(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge},
+		{errors.New("plain"), http.StatusInternalServerError},
+	}
+	for _, test := range tests {
+		if got := StatusCode(test.err); got != test.want {
+			t.Errorf("StatusCode(%v) = %d, want %d", test.err, got, test.want)
+		}
+	}
+}
+
+func TestCodeForStatus(t *testing.T) {
+	tests := []struct {
+		status int
+		want   string
+	}{
+		{http.StatusNotFound, CodeRouteNotFound},
+		{http.StatusMethodNotAllowed, CodeMethodNotAllowed},
+		{http.StatusUnauthorized, CodeUnauthorized},
+		{http.StatusServiceUnavailable, CodeServiceUnavailable},
+		{http.StatusRequestEntityTooLarge, CodeInvalidRequest},
+		{http.StatusBadGateway, CodeInternal},
+	}
+	for _, test := range tests {
+		if got := CodeForStatus(test.status); got != test.want {
+			t.Errorf("CodeForStatus(%d) = %q, want %q", test.status, got, test.want)
+		}
+		if definition := LookupError(test.want); definition.Code != test.want {
+			t.Errorf("%q is missing from the catalog", test.want)
+		}
+	}
+}
@@ -1,13 +1,44 @@
 package types
 
 type Metadata struct {
-	TotalRecords int `json:"total_records"`
-	TotalPages   int `json:"total_pages"`
-	CurrentPage  int `json:"current_page"`
-	PerPage      int `json:"per_page"`
-	HasNext      bool `json:"has_next"`
-	HasPrev      bool `json:"has_prev"`
-	TotalCurrentRecords int `json:"total_current_records"`
+	TotalRecords        int  `json:"total_records"`
+	TotalPages          int  `json:"total_pages"`
+	CurrentPage         int  `json:"current_page"`
+	PerPage             int  `json:"per_page"`
+	HasNext             bool `json:"has_next"`
+	HasPrev             bool `json:"has_prev"`
+	TotalCurrentRecords int  `json:"total_current_records"`
 }
 
-type QueryTypeValidationFunction func(string) bool
\ No newline at end of file
+// NewMetadata describes page of a listing with perPage records per page,
+// currentRecords of which were returned. A partial last page still counts
+// as a page.
+func NewMetadata(totalRecords int, page int, perPage int, currentRecords int) *Metadata {
+	totalPages := 0
+	if perPage > 0 {
+		totalPages = (totalRecords + perPage - 1) / perPage
+	}
+	return &Metadata{
+		TotalRecords:        totalRecords,
+		TotalPages:          totalPages,
+		CurrentPage:         page,
+		PerPage:             perPage,
+		HasNext:             page < totalPages,
+		HasPrev:             page > 1,
+		TotalCurrentRecords: currentRecords,
+	}
+}
+
+// CursorMetadata describes a page of a listing read by cursor. Cursors are
+// omitted when there is nothing more in their direction; keyset listings
+// do not count the records they skip.
+type CursorMetadata struct {
+	PerPage             int    `json:"per_page"`
+	HasNext             bool   `json:"has_next"`
+	HasPrev             bool   `json:"has_prev"`
+	TotalCurrentRecords int    `json:"total_current_records"`
+	NextCursor          string `json:"next_cursor,omitempty"`
+	PrevCursor          string `json:"prev_cursor,omitempty"`
+}
+
+type QueryTypeValidationFunction func(string) bool
@@ -0,0 +1,23 @@
+package types
+
+import "testing"
+
+func TestNewMetadata(t *testing.T) {
+	tests := []struct {
+		total, page, perPage, current int
+		want                          Metadata
+	}{
+		{0, 1, 10, 0, Metadata{TotalPages: 0, CurrentPage: 1, PerPage: 10}},
+		{10, 1, 10, 10, Metadata{TotalRecords: 10, TotalPages: 1, CurrentPage: 1, PerPage: 10, TotalCurrentRecords: 10}},
+		{11, 1, 10, 10, Metadata{TotalRecords: 11, TotalPages: 2, CurrentPage: 1, PerPage: 10, HasNext: true, TotalCurrentRecords: 10}},
+		{11, 2, 10, 1, Metadata{TotalRecords: 11, TotalPages: 2, CurrentPage: 2, PerPage: 10, HasPrev: true, TotalCurrentRecords: 1}},
+		{25, 2, 10, 10, Metadata{TotalRecords: 25, TotalPages: 3, CurrentPage: 2, PerPage: 10, HasNext: true, HasPrev: true, TotalCurrentRecords: 10}},
+		{25, 9, 10, 0, Metadata{TotalRecords: 25, TotalPages: 3, CurrentPage: 9, PerPage: 10, HasPrev: true}},
+		{5, 1, 0, 0, Metadata{TotalRecords: 5, CurrentPage: 1}},
+	}
+	for _, test := range tests {
+		if got := NewMetadata(test.total, test.page, test.perPage, test.current); *got != test.want {
+			t.Errorf("NewMetadata(%d, %d, %d, %d) = %+v, want %+v", test.total, test.page, test.perPage, test.current, *got, test.want)
+		}
+	}
+}
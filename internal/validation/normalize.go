@@ -0,0 +1,136 @@
+package validation
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Normalizers for the `normalize` struct tag, applied by BindAndValidate
+// before any rule is checked.
+var normalizers = map[string]func(string) string{
+	"text":     NormalizeText,
+	"email":    NormalizeEmail,
+	"username": NormalizeUsername,
+}
+
+var reservedUsernames = map[string]bool{}
+
+func init() {
+	for _, name := range []string{
+		"admin", "administrator", "root", "system", "sysadmin", "superuser",
+		"support", "help", "helpdesk", "security", "abuse", "postmaster",
+		"webmaster", "hostmaster", "noreply", "no-reply", "mail", "www",
+		"api", "staff", "moderator", "official", "owner", "billing",
+		"anonymous", "guest", "null", "undefined", "me", "self", "settings",
+		"account", "login", "logout", "signin", "signout", "signup",
+		"register", "reset-password",
+	} {
+		reservedUsernames[skeleton(name)] = true
+	}
+}
+
+// NormalizeText trims surrounding whitespace and applies Unicode NFKC, so
+// compatibility forms such as full-width letters compare equal to their
+// plain counterparts.
+func NormalizeText(value string) string {
+	return strings.TrimSpace(norm.NFKC.String(value))
+}
+
+// NormalizeEmail normalizes the text of an address and case-folds its
+// domain. The local part keeps its case since RFC 5321 leaves it to the
+// receiving server; lookups compare it case-insensitively instead.
+func NormalizeEmail(value string) string {
+	value = NormalizeText(value)
+	at := strings.LastIndexByte(value, '@')
+	if at < 0 {
+		return value
+	}
+	return value[:at+1] + strings.ToLower(value[at+1:])
+}
+
+// NormalizeUsername applies NFKC case folding, so usernames differing only in
+// case or compatibility form are the same username.
+func NormalizeUsername(value string) string {
+	return norm.NFKC.String(cases.Fold().String(NormalizeText(value)))
+}
+
+// IsReservedUsername reports whether username, or a name confusable with
+// it such as "аdmin" spelled with a Cyrillic а, is kept for the service.
+func IsReservedUsername(username string) bool {
+	return reservedUsernames[UsernameSkeleton(username)]
+}
+
+// UsernameSkeleton normalizes username and maps its confusable characters
+// to their Latin prototypes. Usernames with the same skeleton look alike,
+// so the stores keep skeletons unique rather than just usernames.
+func UsernameSkeleton(username string) string {
+	return skeleton(NormalizeUsername(username))
+}
+
+var confusableScripts = []*unicode.RangeTable{
+	unicode.Latin, unicode.Cyrillic, unicode.Greek, unicode.Armenian,
+	unicode.Cherokee, unicode.Han, unicode.Hiragana, unicode.Katakana,
+	unicode.Hangul, unicode.Arabic, unicode.Hebrew,
+}
+
+// IsSingleScript reports whether the letters of value all come from one
+// script. Mixing scripts, as in "pаypal" with a Cyrillic а, is the usual
+// way to imitate another account; digits and punctuation are shared by all
+// scripts and are ignored.
+func IsSingleScript(value string) bool {
+	var script *unicode.RangeTable
+	for _, r := range value {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		for _, table := range confusableScripts {
+			if !unicode.Is(table, r) {
+				continue
+			}
+			if script != nil && script != table {
+				// Japanese is written with Han and kana together.
+				if !isJapanese(script) || !isJapanese(table) {
+					return false
+				}
+			}
+			script = table
+			break
+		}
+	}
+	return true
+}
+
+func isJapanese(table *unicode.RangeTable) bool {
+	return table == unicode.Han || table == unicode.Hiragana || table == unicode.Katakana
+}
+
+// confusables maps characters that render like a Latin letter to that
+// letter. It is a small subset of the Unicode confusables data covering the
+// lookalikes seen in practice. The migrations adding username_skeleton
+// backfill it with the same mapping; a change needs a migration
+// recomputing the column.
+var confusables = map[rune]rune{
+	// Cyrillic
+	'а': 'a', 'в': 'b', 'е': 'e', 'һ': 'h', 'і': 'i', 'ј': 'j', 'к': 'k',
+	'ӏ': 'l', 'м': 'm', 'н': 'h', 'о': 'o', 'р': 'p', 'ԛ': 'q', 'ѕ': 's',
+	'т': 't', 'у': 'y', 'ԝ': 'w', 'х': 'x', 'ԁ': 'd', 'ɡ': 'g', 'ү': 'y',
+	// Greek
+	'α': 'a', 'β': 'b', 'ε': 'e', 'η': 'n', 'ι': 'i', 'κ': 'k', 'ν': 'v',
+	'ο': 'o', 'ρ': 'p', 'τ': 't', 'υ': 'u', 'χ': 'x', 'ω': 'w',
+	// Digits and Latin lookalikes
+	'0': 'o', '1': 'l', 'ı': 'i', 'ℓ': 'l',
+}
+
+// skeleton maps every confusable character to its Latin prototype, so two
+// names with the same skeleton look alike.
+func skeleton(value string) string {
+	return strings.Map(func(r rune) rune {
+		if prototype, ok := confusables[r]; ok {
+			return prototype
+		}
+		return r
+	}, value)
+}
@@ -0,0 +1,93 @@
+package validation
+
+import "testing"
+
+func TestNormalizers(t *testing.T) {
+	tests := []struct {
+		name      string
+		normalize func(string) string
+		input     string
+		want      string
+	}{
+		{"text trims", NormalizeText, "  hello \n", "hello"},
+		{"text applies NFKC", NormalizeText, "ｆｕｌｌ ﬁ ①", "full fi 1"},
+		{"text composes", NormalizeText, "Zoë", "Zoë"},
+		{"email lowercases the domain", NormalizeEmail, " Jane.Doe@EXAMPLE.Com ", "Jane.Doe@example.com"},
+		{"email uses the last at", NormalizeEmail, `"a@b"@EXAMPLE.com`, `"a@b"@example.com`},
+		{"email without an at", NormalizeEmail, " Jane ", "Jane"},
+		{"username folds case", NormalizeUsername, "JaneDoe", "janedoe"},
+		{"username folds width", NormalizeUsername, "ＪＡＮＥ", "jane"},
+		{"username folds beyond lowercase", NormalizeUsername, "Straße", "strasse"},
+		{"username folds greek", NormalizeUsername, "ΣΟΦΙΑ", "σοφια"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.normalize(test.input); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestUsernameSkeleton(t *testing.T) {
+	tests := []struct {
+		a, b string
+		same bool
+	}{
+		{"paypal", "pаypаl", true}, // Cyrillic а
+		{"alice", "ΑLICE", true},   // Greek capital alpha folds to α
+		{"bob", "b0b", true},
+		{"will", "wi11", true},
+		{"admin", "ＡＤＭＩＮ", true},
+		{"alice", "alicia", false},
+		{"jane", "jane_", false},
+	}
+	for _, test := range tests {
+		if same := UsernameSkeleton(test.a) == UsernameSkeleton(test.b); same != test.same {
+			t.Errorf("UsernameSkeleton(%q) == UsernameSkeleton(%q) is %v, want %v", test.a, test.b, same, test.same)
+		}
+	}
+}
+
+func TestIsReservedUsername(t *testing.T) {
+	tests := []struct {
+		username string
+		want     bool
+	}{
+		{"admin", true},
+		{"Admin", true},
+		{"аdmin", true}, // Cyrillic а
+		{"ROOT", true},
+		{"r00t", true},
+		{"ｓｕｐｐｏｒｔ", true},
+		{"admins", false},
+		{"jane", false},
+	}
+	for _, test := range tests {
+		if got := IsReservedUsername(test.username); got != test.want {
+			t.Errorf("IsReservedUsername(%q) = %v, want %v", test.username, got, test.want)
+		}
+	}
+}
+
+func TestIsSingleScript(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{"paypal", true},
+		{"пайпал", true},
+		{"jane_doe-99", true},
+		{"東京すし", true},    // Han and Hiragana
+		{"カタカナ漢字", true},  // Katakana and Han
+		{"pаypal", false}, // Latin with a Cyrillic а
+		{"abcαβγ", false},
+		{"中文한국", false},
+		{"", true},
+	}
+	for _, test := range tests {
+		if got := IsSingleScript(test.value); got != test.want {
+			t.Errorf("IsSingleScript(%q) = %v, want %v", test.value, got, test.want)
+		}
+	}
+}
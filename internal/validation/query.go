@@ -0,0 +1,297 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// Query error codes for typed binding, next to the ones ValidateQuery uses.
+const (
+	QueryCodeRepeated   = "repeated"
+	QueryCodeTooSmall   = "too_small"
+	QueryCodeTooLarge   = "too_large"
+	QueryCodeNotAllowed = "not_allowed"
+	// QueryCodeInvalidCursor is reported by handlers for pagination cursors
+	// that fail verification.
+	QueryCodeInvalidCursor = "invalid_cursor"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Bind parses the query string into dst, a pointer to a struct, and returns
+// every problem found in the same form as ValidateQuery. Fields are bound
+// from their `query` tag and parsed according to their Go type: strings,
+// integers, floats, booleans ("true", "false", "1", "0") and time.Time
+// (RFC 3339 or a 2006-01-02 date). More tags refine a field:
+//
+//	query:"name,comma"  also split each value on commas, for slices
+//	type:"name"         check raw values with a registered type validator
+//	default:"value"     used when the parameter is absent
+//	min:"n", max:"n"    bounds on the value, or on the length of a string
+//	enum:"a b c"        the space separated values allowed
+//
+// Slice fields collect repeated parameters, e.g. ?status=a&status=b; any
+// other field may be given once. Pointer fields stay nil when the parameter
+// is absent, telling "not given" apart from a zero value. Parameters
+// without a field are reported as unexpected. A malformed tag is returned
+// as an error wrapping ErrInvalidTag.
+func (qv *QueryValidator) Bind(c fiber.Ctx, dst any) ([]QueryValidationError, error) {
+	value := reflect.ValueOf(dst)
+	if value.Kind() != reflect.Pointer || value.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: %T is not a pointer to a struct", ErrInvalidTag, dst)
+	}
+	value = value.Elem()
+
+	var names []string
+	params := make(map[string][]string)
+	c.Request().URI().QueryArgs().VisitAll(func(key []byte, arg []byte) {
+		name := string(key)
+		if _, seen := params[name]; !seen {
+			names = append(names, name)
+		}
+		params[name] = append(params[name], string(arg))
+	})
+
+	var validationErrors []QueryValidationError
+	bound := make(map[string]bool)
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		name, options, _ := strings.Cut(field.Tag.Get("query"), ",")
+		if name == "" || name == "-" || !field.IsExported() {
+			continue
+		}
+		bound[name] = true
+
+		raw, given := params[name]
+		if options == "comma" {
+			raw = splitComma(raw)
+		}
+		if !given {
+			defaultValue, ok := field.Tag.Lookup("default")
+			if !ok {
+				continue
+			}
+			raw = []string{defaultValue}
+			if options == "comma" {
+				raw = splitComma(raw)
+			}
+		}
+
+		fieldErrors, err := qv.bindField(value.Field(i), field, name, raw)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s.%s: %v", ErrInvalidTag, value.Type().Name(), field.Name, err)
+		}
+		validationErrors = append(validationErrors, fieldErrors...)
+	}
+
+	for _, name := range names {
+		if bound[name] {
+			continue
+		}
+		queryError := QueryValidationError{Parameter: name, Value: strings.Join(params[name], ",")}
+		if !qv.validateParamName(name) {
+			queryError.Code = QueryCodeInvalidName
+			queryError.Message = "invalid parameter name format"
+		} else {
+			queryError.Code = QueryCodeUnexpected
+			queryError.Message = "unexpected parameter"
+		}
+		validationErrors = append(validationErrors, queryError)
+	}
+	return validationErrors, nil
+}
+
+func (qv *QueryValidator) bindField(target reflect.Value, field reflect.StructField, name string, raw []string) ([]QueryValidationError, error) {
+	elemType := target.Type()
+	if elemType.Kind() == reflect.Slice || elemType.Kind() == reflect.Pointer {
+		elemType = elemType.Elem()
+	}
+	if target.Kind() != reflect.Slice && len(raw) > 1 {
+		return []QueryValidationError{{
+			Parameter: name,
+			Value:     strings.Join(raw, ","),
+			Code:      QueryCodeRepeated,
+			Message:   "parameter may only be given once",
+		}}, nil
+	}
+
+	var validationErrors []QueryValidationError
+	values := reflect.MakeSlice(reflect.SliceOf(elemType), 0, len(raw))
+	for _, rawValue := range raw {
+		parsed, queryError, err := qv.parseValue(elemType, field, rawValue)
+		if err != nil {
+			return nil, err
+		}
+		if queryError != nil {
+			queryError.Parameter = name
+			validationErrors = append(validationErrors, *queryError)
+			continue
+		}
+		values = reflect.Append(values, parsed)
+	}
+	if len(validationErrors) > 0 {
+		return validationErrors, nil
+	}
+
+	switch {
+	case target.Kind() == reflect.Slice:
+		target.Set(values)
+	case values.Len() == 1 && target.Kind() == reflect.Pointer:
+		pointer := reflect.New(elemType)
+		pointer.Elem().Set(values.Index(0))
+		target.Set(pointer)
+	case values.Len() == 1:
+		target.Set(values.Index(0))
+	}
+	return nil, nil
+}
+
+// parseValue converts one raw value and checks it against the field's tags.
+// A rejected value is reported as a QueryValidationError; err is only set
+// for malformed tags.
+func (qv *QueryValidator) parseValue(valueType reflect.Type, field reflect.StructField, raw string) (reflect.Value, *QueryValidationError, error) {
+	typeName := field.Tag.Get("type")
+	if typeName == "" {
+		typeName = queryTypeName(valueType)
+	}
+	invalid := &QueryValidationError{
+		Value:   raw,
+		Code:    QueryCodeInvalidType,
+		Message: fmt.Sprintf("invalid value for type %s", typeName),
+		Params:  []string{typeName},
+	}
+
+	if name := field.Tag.Get("type"); name != "" {
+		validator, ok := qv.typeValidators[name]
+		if !ok {
+			return reflect.Value{}, nil, fmt.Errorf("no type validator registered as %q", name)
+		}
+		if !validator(raw) {
+			return reflect.Value{}, invalid, nil
+		}
+	}
+
+	parsed := reflect.New(valueType).Elem()
+	var number float64
+	switch {
+	case valueType == timeType:
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			if t, err = time.Parse(time.DateOnly, raw); err != nil {
+				return reflect.Value{}, invalid, nil
+			}
+		}
+		parsed.Set(reflect.ValueOf(t))
+	case valueType.Kind() == reflect.String:
+		parsed.SetString(raw)
+		number = float64(len([]rune(raw)))
+	case valueType.Kind() == reflect.Bool:
+		switch strings.ToLower(raw) {
+		case "true", "1":
+			parsed.SetBool(true)
+		case "false", "0":
+			parsed.SetBool(false)
+		default:
+			return reflect.Value{}, invalid, nil
+		}
+	case parsed.CanInt():
+		n, err := strconv.ParseInt(raw, 10, valueType.Bits())
+		if err != nil {
+			return reflect.Value{}, invalid, nil
+		}
+		parsed.SetInt(n)
+		number = float64(n)
+	case parsed.CanUint():
+		n, err := strconv.ParseUint(raw, 10, valueType.Bits())
+		if err != nil {
+			return reflect.Value{}, invalid, nil
+		}
+		parsed.SetUint(n)
+		number = float64(n)
+	case parsed.CanFloat():
+		n, err := strconv.ParseFloat(raw, valueType.Bits())
+		if err != nil {
+			return reflect.Value{}, invalid, nil
+		}
+		parsed.SetFloat(n)
+		number = n
+	default:
+		return reflect.Value{}, nil, fmt.Errorf("unsupported query field type %v", valueType)
+	}
+
+	if enum, ok := field.Tag.Lookup("enum"); ok {
+		options := strings.Fields(enum)
+		if !slices.Contains(options, raw) {
+			list := strings.Join(options, ", ")
+			return reflect.Value{}, &QueryValidationError{
+				Value:   raw,
+				Code:    QueryCodeNotAllowed,
+				Message: "value must be one of: " + list,
+				Params:  []string{list},
+			}, nil
+		}
+	}
+
+	for _, bound := range []string{"min", "max"} {
+		limitTag, ok := field.Tag.Lookup(bound)
+		if !ok {
+			continue
+		}
+		if valueType == timeType || valueType.Kind() == reflect.Bool {
+			return reflect.Value{}, nil, fmt.Errorf("%s does not apply to %v", bound, valueType)
+		}
+		limit, err := strconv.ParseFloat(limitTag, 64)
+		if err != nil {
+			return reflect.Value{}, nil, errors.New(bound + " needs a number")
+		}
+		if bound == "min" && number < limit {
+			return reflect.Value{}, &QueryValidationError{
+				Value:   raw,
+				Code:    QueryCodeTooSmall,
+				Message: "value must be at least " + limitTag,
+				Params:  []string{limitTag},
+			}, nil
+		}
+		if bound == "max" && number > limit {
+			return reflect.Value{}, &QueryValidationError{
+				Value:   raw,
+				Code:    QueryCodeTooLarge,
+				Message: "value must be at most " + limitTag,
+				Params:  []string{limitTag},
+			}, nil
+		}
+	}
+
+	return parsed, nil, nil
+}
+
+func queryTypeName(valueType reflect.Type) string {
+	switch {
+	case valueType == timeType:
+		return "timestamp"
+	case valueType.Kind() == reflect.Bool:
+		return "boolean"
+	case valueType.Kind() == reflect.String:
+		return "string"
+	}
+	return "number"
+}
+
+func splitComma(values []string) []string {
+	var split []string
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				split = append(split, part)
+			}
+		}
+	}
+	return split
+}
@@ -0,0 +1,126 @@
+package validation
+
+import (
+	"errors"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+type listQuery struct {
+	Page     int        `query:"page" default:"1" min:"1"`
+	PerPage  int        `query:"per_page" max:"100"`
+	Sort     string     `query:"sort" enum:"name created_at"`
+	Search   string     `query:"search" max:"5"`
+	Active   *bool      `query:"active"`
+	Status   []string   `query:"status"`
+	Tags     []string   `query:"tags,comma"`
+	Ids      []int      `query:"id"`
+	From     time.Time  `query:"from"`
+	Score    float64    `query:"score"`
+	Until    *time.Time `query:"until"`
+	internal string
+}
+
+// bindQuery binds rawQuery into dst as a handler would.
+func bindQuery(t *testing.T, rawQuery string, dst any) ([]QueryValidationError, error) {
+	t.Helper()
+	var validationErrors []QueryValidationError
+	var bindErr error
+	app := fiber.New()
+	app.Get("/", func(c fiber.Ctx) error {
+		validationErrors, bindErr = NewQueryValidator().Bind(c, dst)
+		return nil
+	})
+	if _, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/?"+rawQuery, nil)); err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	return validationErrors, bindErr
+}
+
+func TestQueryBind(t *testing.T) {
+	yes := true
+	until := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		query      string
+		want       listQuery
+		wantErrors []string
+	}{
+		{"", listQuery{Page: 1}, nil},
+		{
+			"page=3&per_page=20&sort=name&active=true&from=2024-05-01&until=2024-05-01T12:00:00Z&score=0.5",
+			listQuery{Page: 3, PerPage: 20, Sort: "name", Active: &yes, From: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), Until: &until, Score: 0.5},
+			nil,
+		},
+		{"status=a&status=b&tags=x,%20y,,z&id=1&id=2", listQuery{Page: 1, Status: []string{"a", "b"}, Tags: []string{"x", "y", "z"}, Ids: []int{1, 2}}, nil},
+		{"page=0", listQuery{}, []string{"page:too_small"}},
+		{"per_page=101", listQuery{Page: 1}, []string{"per_page:too_large"}},
+		{"search=%C3%BC%C3%BC%C3%BC%C3%BC%C3%BC", listQuery{Page: 1, Search: "üüüüü"}, nil},
+		{"search=abcdef", listQuery{Page: 1}, []string{"search:too_large"}},
+		{"sort=email", listQuery{Page: 1}, []string{"sort:not_allowed"}},
+		{"page=two&active=maybe&from=yesterday", listQuery{}, []string{"page:invalid_type", "active:invalid_type", "from:invalid_type"}},
+		{"page=1&page=2", listQuery{}, []string{"page:repeated"}},
+		{"id=1&id=x", listQuery{Page: 1}, []string{"id:invalid_type"}},
+		{"internal=1&9lives=1", listQuery{Page: 1}, []string{"internal:unexpected", "9lives:invalid_name"}},
+	}
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			var got listQuery
+			validationErrors, err := bindQuery(t, test.query, &got)
+			if err != nil {
+				t.Fatalf("Bind: %v", err)
+			}
+			var gotErrors []string
+			for _, validationError := range validationErrors {
+				gotErrors = append(gotErrors, validationError.Parameter+":"+validationError.Code)
+			}
+			if !slices.Equal(gotErrors, test.wantErrors) {
+				t.Errorf("errors = %v, want %v", gotErrors, test.wantErrors)
+			}
+			if test.wantErrors == nil && !equalListQuery(got, test.want) {
+				t.Errorf("bound %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func equalListQuery(a, b listQuery) bool {
+	return a.Page == b.Page && a.PerPage == b.PerPage && a.Sort == b.Sort && a.Search == b.Search &&
+		(a.Active == nil) == (b.Active == nil) && (a.Active == nil || *a.Active == *b.Active) &&
+		slices.Equal(a.Status, b.Status) && slices.Equal(a.Tags, b.Tags) && slices.Equal(a.Ids, b.Ids) &&
+		a.From.Equal(b.From) && a.Score == b.Score &&
+		(a.Until == nil) == (b.Until == nil) && (a.Until == nil || a.Until.Equal(*b.Until))
+}
+
+func TestQueryBindRejectsMalformedTags(t *testing.T) {
+	tests := []struct {
+		name string
+		dst  any
+	}{
+		{"not a pointer", listQuery{}},
+		{"unregistered type validator", &struct {
+			Id string `query:"id" type:"nope"`
+		}{}},
+		{"bound on a boolean", &struct {
+			Flag bool `query:"flag" min:"1"`
+		}{}},
+		{"non-numeric bound", &struct {
+			Page int `query:"page" min:"one"`
+		}{}},
+		{"unsupported type", &struct {
+			Values map[string]string `query:"values"`
+		}{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Tags are checked as values are parsed, so every field gets one.
+			_, err := bindQuery(t, "id=1&flag=true&page=1&values=a", test.dst)
+			if !errors.Is(err, ErrInvalidTag) {
+				t.Errorf("Bind = %v, want ErrInvalidTag", err)
+			}
+		})
+	}
+}
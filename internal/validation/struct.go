@@ -0,0 +1,353 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// ErrInvalidTag is wrapped by errors about malformed `validate` tags. Those
+// are programming errors, so handlers report them as internal errors.
+var ErrInvalidTag = errors.New("invalid validate tag")
+
+var (
+	patternsMu sync.RWMutex
+	patterns   = map[string]*regexp.Regexp{
+		"username": regexp.MustCompile(`^[\p{L}\p{N}][\p{L}\p{M}\p{N}._-]*$`),
+	}
+
+	structRulesCache sync.Map
+)
+
+// RegisterPattern makes pattern available to the regexp=<name> rule. Tags
+// refer to patterns by name because a pattern may contain the commas that
+// separate rules.
+func RegisterPattern(name string, pattern string) error {
+	regex, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid pattern for %s: %v", name, err)
+	}
+	patternsMu.Lock()
+	defer patternsMu.Unlock()
+	patterns[name] = regex
+	return nil
+}
+
+type fieldRule struct {
+	name  string
+	param string
+}
+
+type structField struct {
+	index      int
+	name       string
+	normalizer string
+	rules      []fieldRule
+}
+
+type structRules struct {
+	fields  []structField
+	byField map[string]int
+	names   map[string]bool
+}
+
+// BindAndValidate decodes the JSON request body into model, a pointer to a
+// struct, and checks it against the struct's `validate` tags. Body members
+// that match no json tag of the struct are rejected with an
+// InvalidFieldError. Rule failures are returned as fields, one per failing
+// struct field, and a nil error.
+//
+// Rules are separated by commas:
+//
+//	required          the value must not be the zero value
+//	email             a bare address such as user@example.com
+//	min=N, max=N      length in characters for strings, value for numbers
+//	maxbytes=N        length in bytes of a string
+//	regexp=NAME       matches the pattern registered as NAME
+//	oneof=A B C       one of the space separated values
+//	eqfield=F         equal to struct field F
+//	nefield=F         different from struct field F
+//	required_with=F   required when struct field F is set
+//	singlescript      letters all come from one script
+//	notreserved       not a reserved username or a lookalike of one
+//
+// Every rule but required and required_with passes for an empty value, so
+// optional fields only need their rules when given.
+//
+// String fields tagged `normalize:"text"`, "email" or "username" are
+// normalized with NormalizeText, NormalizeEmail or NormalizeUsername before
+// the rules run, and model keeps the normalized values.
+func BindAndValidate(c fiber.Ctx, model any) ([]ValidationErrorField, error) {
+	rules, err := rulesFor(reflect.TypeOf(model))
+	if err != nil {
+		return nil, err
+	}
+
+	body := c.BodyRaw()
+	var rawFields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &rawFields); err != nil {
+		return nil, err
+	}
+	if rawFields == nil {
+		return nil, errors.New("request body must be a JSON object")
+	}
+
+	var unknownFields []string
+	for field := range rawFields {
+		if !rules.names[field] {
+			unknownFields = append(unknownFields, field)
+		}
+	}
+	if len(unknownFields) > 0 {
+		sort.Strings(unknownFields)
+		return nil, NewInvalidFieldError(unknownFields)
+	}
+
+	if err := json.Unmarshal(body, model); err != nil {
+		return nil, err
+	}
+
+	value := reflect.ValueOf(model).Elem()
+	for _, field := range rules.fields {
+		if field.normalizer != "" {
+			fieldValue := value.Field(field.index)
+			fieldValue.SetString(normalizers[field.normalizer](fieldValue.String()))
+		}
+	}
+
+	return ValidateStruct(model)
+}
+
+// ValidateStruct checks model, a struct or pointer to one, against its
+// `validate` tags. See BindAndValidate for the rules.
+func ValidateStruct(model any) ([]ValidationErrorField, error) {
+	value := reflect.ValueOf(model)
+	rules, err := rulesFor(value.Type())
+	if err != nil {
+		return nil, err
+	}
+	for value.Kind() == reflect.Pointer {
+		value = value.Elem()
+	}
+
+	validator := NewErrorValidator()
+	for _, field := range rules.fields {
+		for _, rule := range field.rules {
+			code, message, params := checkRule(rule, field, value, rules)
+			if code != "" {
+				validator.CheckCode(false, field.name, code, message, params...)
+				break
+			}
+		}
+	}
+	return validator.ValidationErrorField, nil
+}
+
+// checkRule returns an empty code when the field satisfies rule.
+func checkRule(rule fieldRule, field structField, parent reflect.Value, rules *structRules) (code string, message string, params []string) {
+	value := parent.Field(field.index)
+	label := strings.ReplaceAll(field.name, "_", " ")
+	empty := value.IsZero()
+	if empty && rule.name != "required" && rule.name != "required_with" {
+		return "", "", nil
+	}
+
+	switch rule.name {
+	case "required":
+		if empty {
+			return "required", label + " must be provided", nil
+		}
+	case "required_with":
+		if empty && !parent.Field(rules.byField[rule.param]).IsZero() {
+			return "required_with", fmt.Sprintf("%s must be provided with %s", label, rule.param), []string{rule.param}
+		}
+	case "email":
+		if !isEmail(value.String()) {
+			return "email", label + " must be a valid email address", nil
+		}
+	case "min", "max":
+		limit, _ := strconv.ParseFloat(rule.param, 64)
+		if (rule.name == "min" && measure(value) >= limit) || (rule.name == "max" && measure(value) <= limit) {
+			return "", "", nil
+		}
+		bound := "at least"
+		if rule.name == "max" {
+			bound = "at most"
+		}
+		if value.Kind() == reflect.String {
+			return rule.name, fmt.Sprintf("%s must be %s %s characters", label, bound, rule.param), []string{rule.param}
+		}
+		return rule.name + "_value", fmt.Sprintf("%s must be %s %s", label, bound, rule.param), []string{rule.param}
+	case "maxbytes":
+		limit, _ := strconv.Atoi(rule.param)
+		if len(value.String()) > limit {
+			return "maxbytes", fmt.Sprintf("%s must be at most %s bytes", label, rule.param), []string{rule.param}
+		}
+	case "singlescript":
+		if !IsSingleScript(value.String()) {
+			return "singlescript", label + " mixes characters from different scripts", nil
+		}
+	case "notreserved":
+		if IsReservedUsername(value.String()) {
+			return "notreserved", label + " is reserved", nil
+		}
+	case "regexp":
+		patternsMu.RLock()
+		regex := patterns[rule.param]
+		patternsMu.RUnlock()
+		if !regex.MatchString(value.String()) {
+			return "regexp", label + " has an invalid format", nil
+		}
+	case "oneof":
+		options := strings.Fields(rule.param)
+		if !slices.Contains(options, fmt.Sprint(value.Interface())) {
+			list := strings.Join(options, ", ")
+			return "oneof", fmt.Sprintf("%s must be one of: %s", label, list), []string{list}
+		}
+	case "eqfield":
+		if !value.Equal(parent.Field(rules.byField[rule.param])) {
+			return "eqfield", fmt.Sprintf("%s must match %s", label, rule.param), []string{rule.param}
+		}
+	case "nefield":
+		if value.Equal(parent.Field(rules.byField[rule.param])) {
+			return "nefield", fmt.Sprintf("%s must differ from %s", label, rule.param), []string{rule.param}
+		}
+	}
+	return "", "", nil
+}
+
+// measure is the length in characters of a string and the value of a number.
+func measure(value reflect.Value) float64 {
+	switch value.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(value.String()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint())
+	case reflect.Float32, reflect.Float64:
+		return value.Float()
+	case reflect.Slice, reflect.Map:
+		return float64(value.Len())
+	}
+	return 0
+}
+
+// isEmail accepts a bare address with a dotted domain, not the display name
+// forms net/mail also parses.
+func isEmail(value string) bool {
+	address, err := mail.ParseAddress(value)
+	if err != nil || address.Address != value {
+		return false
+	}
+	_, domain, _ := strings.Cut(value, "@")
+	return strings.Contains(strings.Trim(domain, "."), ".")
+}
+
+// rulesFor parses and caches the json names and `validate` tags of a struct
+// type, reporting malformed tags wrapped in ErrInvalidTag.
+func rulesFor(modelType reflect.Type) (*structRules, error) {
+	for modelType != nil && modelType.Kind() == reflect.Pointer {
+		modelType = modelType.Elem()
+	}
+	if modelType == nil || modelType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: %v is not a struct", ErrInvalidTag, modelType)
+	}
+	if cached, ok := structRulesCache.Load(modelType); ok {
+		return cached.(*structRules), nil
+	}
+
+	rules := &structRules{byField: make(map[string]int), names: make(map[string]bool)}
+	for i := 0; i < modelType.NumField(); i++ {
+		field := modelType.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		rules.names[name] = true
+		rules.byField[name] = i
+
+		tag := field.Tag.Get("validate")
+		normalizer := field.Tag.Get("normalize")
+		if tag == "" && normalizer == "" {
+			continue
+		}
+		parsed := structField{index: i, name: name, normalizer: normalizer}
+		if normalizer != "" {
+			if _, ok := normalizers[normalizer]; !ok || field.Type.Kind() != reflect.String {
+				return nil, fmt.Errorf("%w: %s.%s: unknown normalizer %q for %v", ErrInvalidTag, modelType.Name(), name, normalizer, field.Type)
+			}
+		}
+		if tag == "" {
+			rules.fields = append(rules.fields, parsed)
+			continue
+		}
+		for _, part := range strings.Split(tag, ",") {
+			ruleName, param, _ := strings.Cut(strings.TrimSpace(part), "=")
+			parsed.rules = append(parsed.rules, fieldRule{name: ruleName, param: param})
+		}
+		rules.fields = append(rules.fields, parsed)
+	}
+
+	for _, field := range rules.fields {
+		fieldType := modelType.Field(field.index).Type
+		for _, rule := range field.rules {
+			if err := checkTag(rule, fieldType, rules); err != nil {
+				return nil, fmt.Errorf("%w: %s.%s: %v", ErrInvalidTag, modelType.Name(), field.name, err)
+			}
+		}
+	}
+
+	cached, _ := structRulesCache.LoadOrStore(modelType, rules)
+	return cached.(*structRules), nil
+}
+
+func checkTag(rule fieldRule, fieldType reflect.Type, rules *structRules) error {
+	switch rule.name {
+	case "required":
+	case "email", "regexp", "maxbytes", "singlescript", "notreserved":
+		if fieldType.Kind() != reflect.String {
+			return fmt.Errorf("%s needs a string field", rule.name)
+		}
+		if rule.name == "regexp" {
+			patternsMu.RLock()
+			_, ok := patterns[rule.param]
+			patternsMu.RUnlock()
+			if !ok {
+				return fmt.Errorf("no pattern registered as %q", rule.param)
+			}
+		}
+		if _, err := strconv.Atoi(rule.param); rule.name == "maxbytes" && err != nil {
+			return fmt.Errorf("maxbytes needs an integer, got %q", rule.param)
+		}
+	case "min", "max":
+		if _, err := strconv.ParseFloat(rule.param, 64); err != nil {
+			return fmt.Errorf("%s needs a number, got %q", rule.name, rule.param)
+		}
+	case "oneof":
+		if len(strings.Fields(rule.param)) == 0 {
+			return errors.New("oneof needs at least one value")
+		}
+	case "eqfield", "nefield", "required_with":
+		if _, ok := rules.byField[rule.param]; !ok {
+			return fmt.Errorf("%s refers to unknown field %q", rule.name, rule.param)
+		}
+	default:
+		return fmt.Errorf("unknown rule %q", rule.name)
+	}
+	return nil
+}
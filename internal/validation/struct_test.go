@@ -0,0 +1,185 @@
+package validation
+
+import (
+	"errors"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+type signupForm struct {
+	Email           string `json:"email" validate:"required,email" normalize:"email"`
+	Username        string `json:"username" validate:"required,min=3,max=20,regexp=username,singlescript,notreserved" normalize:"username"`
+	Password        string `json:"password" validate:"required,min=8,maxbytes=72"`
+	ConfirmPassword string `json:"confirm_password" validate:"eqfield=password"`
+	OldPassword     string `json:"old_password" validate:"nefield=password"`
+	Role            string `json:"role" validate:"oneof=user admin"`
+	Age             int    `json:"age" validate:"min=13,max=130"`
+	Phone           string `json:"phone"`
+	PhoneCountry    string `json:"phone_country" validate:"required_with=phone"`
+}
+
+func validSignupForm() signupForm {
+	return signupForm{
+		Email:           "jane@example.com",
+		Username:        "jane",
+		Password:        "correct horse",
+		ConfirmPassword: "correct horse",
+	}
+}
+
+func TestValidateStruct(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(form *signupForm)
+		want   []string
+	}{
+		{"valid", func(form *signupForm) {}, nil},
+		{"required", func(form *signupForm) { form.Email, form.Password, form.ConfirmPassword = "", "", "" }, []string{"email:required", "password:required"}},
+		{"email", func(form *signupForm) { form.Email = "Jane <jane@example.com>" }, []string{"email:email"}},
+		{"email without a dotted domain", func(form *signupForm) { form.Email = "jane@localhost" }, []string{"email:email"}},
+		{"min counts characters", func(form *signupForm) { form.Username = "жё" }, []string{"username:min"}},
+		{"max", func(form *signupForm) { form.Username = strings.Repeat("a", 21) }, []string{"username:max"}},
+		{"first failing rule only", func(form *signupForm) { form.Username = "-a" }, []string{"username:min"}},
+		{"regexp", func(form *signupForm) { form.Username = "-jane" }, []string{"username:regexp"}},
+		{"singlescript", func(form *signupForm) { form.Username = "pаypal" }, []string{"username:singlescript"}},
+		{"notreserved", func(form *signupForm) { form.Username = "Admin" }, []string{"username:notreserved"}},
+		{"maxbytes counts bytes", func(form *signupForm) {
+			form.Password = strings.Repeat("ж", 40)
+			form.ConfirmPassword = form.Password
+		}, []string{"password:maxbytes"}},
+		{"eqfield", func(form *signupForm) { form.ConfirmPassword = "other" }, []string{"confirm_password:eqfield"}},
+		{"nefield", func(form *signupForm) { form.OldPassword = form.Password }, []string{"old_password:nefield"}},
+		{"oneof", func(form *signupForm) { form.Role = "root" }, []string{"role:oneof"}},
+		{"number bounds", func(form *signupForm) { form.Age = 7 }, []string{"age:min_value"}},
+		{"required_with", func(form *signupForm) { form.Phone = "555" }, []string{"phone_country:required_with"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			form := validSignupForm()
+			test.change(&form)
+			fields, err := ValidateStruct(&form)
+			if err != nil {
+				t.Fatalf("ValidateStruct: %v", err)
+			}
+			var got []string
+			for _, field := range fields {
+				got = append(got, field.Field+":"+field.Code)
+			}
+			if !slices.Equal(got, test.want) {
+				t.Errorf("ValidateStruct = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestValidateStructRejectsMalformedTags(t *testing.T) {
+	tests := []struct {
+		name  string
+		model any
+	}{
+		{"unknown rule", &struct {
+			Name string `json:"name" validate:"shiny"`
+		}{}},
+		{"string rule on a number", &struct {
+			Count int `json:"count" validate:"email"`
+		}{}},
+		{"unregistered pattern", &struct {
+			Name string `json:"name" validate:"regexp=nope"`
+		}{}},
+		{"non-numeric bound", &struct {
+			Name string `json:"name" validate:"min=few"`
+		}{}},
+		{"unknown field reference", &struct {
+			Name string `json:"name" validate:"eqfield=other"`
+		}{}},
+		{"unknown normalizer", &struct {
+			Name string `json:"name" normalize:"shout"`
+		}{}},
+		{"not a struct", new(string)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := ValidateStruct(test.model); !errors.Is(err, ErrInvalidTag) {
+				t.Errorf("ValidateStruct = %v, want ErrInvalidTag", err)
+			}
+		})
+	}
+}
+
+func TestBindAndValidate(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		wantForm      signupForm
+		wantFields    []string
+		wantUnknown   []string
+		wantDecodeErr bool
+	}{
+		{
+			name:     "normalizes before validating",
+			body:     `{"email":" Jane@EXAMPLE.com ","username":"ＪＡＮＥ","password":"correct horse","confirm_password":"correct horse"}`,
+			wantForm: signupForm{Email: "Jane@example.com", Username: "jane", Password: "correct horse", ConfirmPassword: "correct horse"},
+		},
+		{
+			name:       "reports rule failures",
+			body:       `{"email":"jane","username":"jane","password":"short"}`,
+			wantForm:   signupForm{Email: "jane", Username: "jane", Password: "short"},
+			wantFields: []string{"email", "password"},
+		},
+		{
+			name:        "rejects unknown members",
+			body:        `{"email":"jane@example.com","is_admin":true,"extra":1}`,
+			wantUnknown: []string{"extra", "is_admin"},
+		},
+		{name: "rejects non-objects", body: `["jane"]`, wantDecodeErr: true},
+		{name: "rejects null", body: `null`, wantDecodeErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var form signupForm
+			var fields []ValidationErrorField
+			var bindErr error
+			app := fiber.New()
+			app.Post("/", func(c fiber.Ctx) error {
+				fields, bindErr = BindAndValidate(c, &form)
+				return nil
+			})
+			request := httptest.NewRequest(fiber.MethodPost, "/", strings.NewReader(test.body))
+			request.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			if _, err := app.Test(request); err != nil {
+				t.Fatalf("app.Test: %v", err)
+			}
+
+			var invalidField *InvalidFieldError
+			switch {
+			case test.wantUnknown != nil:
+				if !errors.As(bindErr, &invalidField) || !slices.Equal(invalidField.Fields, test.wantUnknown) {
+					t.Errorf("BindAndValidate = %v, want unknown fields %v", bindErr, test.wantUnknown)
+				}
+				return
+			case test.wantDecodeErr:
+				if bindErr == nil {
+					t.Error("BindAndValidate accepted the body")
+				}
+				return
+			case bindErr != nil:
+				t.Fatalf("BindAndValidate: %v", bindErr)
+			}
+
+			if form != test.wantForm {
+				t.Errorf("bound %+v, want %+v", form, test.wantForm)
+			}
+			var got []string
+			for _, field := range fields {
+				got = append(got, field.Field)
+			}
+			if !slices.Equal(got, test.wantFields) {
+				t.Errorf("failing fields = %v, want %v", got, test.wantFields)
+			}
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fiber-auth-api/internal/types"
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/gofiber/fiber/v3"
@@ -14,7 +15,10 @@ import (
 // Json field validation
 type ValidationErrorField struct {
 	Field   string `json:"field"`
+	Code    string `json:"code,omitempty"`
 	Message string `json:"message"`
+	// Params are the message parameters after the field name.
+	Params []string `json:"-"`
 }
 
 type ValidationError struct {
@@ -35,6 +39,14 @@ func (validatorError *ValidationError) Check(ok bool, field string, message stri
 	}
 }
 
+// CheckCode is Check for errors with a code, which lets the message be
+// localized as validation.<code>; message is the English fallback.
+func (validatorError *ValidationError) CheckCode(ok bool, field string, code string, message string, params ...string) {
+	if !ok {
+		validatorError.ValidationErrorField = append(validatorError.ValidationErrorField, ValidationErrorField{Field: field, Code: code, Message: message, Params: params})
+	}
+}
+
 func (validatorError *ValidationError) IsValid() bool {
 	return len(validatorError.ValidationErrorField) == 0
 }
@@ -55,33 +67,34 @@ func IsInvalidFieldError(err error) (*InvalidFieldError, bool) {
 }
 
 func InvalidFieldValidation(c fiber.Ctx, expectedFields map[string]bool, dataModel interface{}) error {
-    body := c.BodyRaw()
-    var rawFields map[string]interface{}
+	body := c.BodyRaw()
+	var rawFields map[string]interface{}
 
-    if err := json.Unmarshal(body, &rawFields); err != nil {
-        return err
-    }
+	if err := json.Unmarshal(body, &rawFields); err != nil {
+		return err
+	}
 
-    unknownFields := findUnknownFields(rawFields, expectedFields)
-    if len(unknownFields) > 0 {
-        return NewInvalidFieldError(unknownFields)
-    }
+	unknownFields := findUnknownFields(rawFields, expectedFields)
+	if len(unknownFields) > 0 {
+		return NewInvalidFieldError(unknownFields)
+	}
 
-    if err := json.Unmarshal(body, &dataModel); err != nil {
-        return err
-    }
+	if err := json.Unmarshal(body, &dataModel); err != nil {
+		return err
+	}
 
-    return nil
+	return nil
 }
 
 func findUnknownFields(rawFields map[string]interface{}, expectedFields map[string]bool) []string {
-    var unknownFields []string
-    for field := range rawFields {
-        if _, exists := expectedFields[field]; !exists {
-            unknownFields = append(unknownFields, field)
-        }
-    }
-    return unknownFields
+	var unknownFields []string
+	for field := range rawFields {
+		if _, exists := expectedFields[field]; !exists {
+			unknownFields = append(unknownFields, field)
+		}
+	}
+	sort.Strings(unknownFields)
+	return unknownFields
 }
 
 func (e *InvalidFieldError) Error() string {
@@ -90,107 +103,117 @@ func (e *InvalidFieldError) Error() string {
 
 // Query validation
 type QueryValidationError struct {
-    Parameter string `json:"parameter"`
-    Value     string `json:"value"`
-    Message   string `json:"message"`
-}
+	Parameter string   `json:"parameter"`
+	Value     string   `json:"value"`
+	Code      string   `json:"code"`
+	Message   string   `json:"message"`
+	Params    []string `json:"-"`
+}
+
+// Query error codes; messages are localized as query.<code>.
+const (
+	QueryCodeInvalidName = "invalid_name"
+	QueryCodeUnexpected  = "unexpected"
+	QueryCodeInvalidType = "invalid_type"
+)
 
 type QueryValidator struct {
-    paramPatterns map[string]*regexp.Regexp
-    typeValidators map[string]types.QueryTypeValidationFunction
+	paramPatterns  map[string]*regexp.Regexp
+	typeValidators map[string]types.QueryTypeValidationFunction
 }
 
 func NewQueryValidator() *QueryValidator {
-    qv := &QueryValidator{
-        paramPatterns: make(map[string]*regexp.Regexp),
-        typeValidators: make(map[string]types.QueryTypeValidationFunction),
-    }
-    
-    qv.AddParamPattern("default", `^[a-zA-Z][a-zA-Z0-9_]*$`)
-    
-    qv.typeValidators["number"] = func(v string) bool {
-        matched, _ := regexp.MatchString(`^-?\d+(\.\d+)?$`, v)
-        return matched
-    }
-
-    
-
-    qv.typeValidators["boolean"] = func(v string) bool {
-        v = strings.ToLower(v)
-        return v == "true" || v == "false" || v == "1" || v == "0"
-    }
-
-    qv.typeValidators["date"] = func(v string) bool {
-        matched, _ := regexp.MatchString(`^\d{4}-\d{2}-\d{2}$`, v)
-        return matched
-    }
-    
-    return qv
+	qv := &QueryValidator{
+		paramPatterns:  make(map[string]*regexp.Regexp),
+		typeValidators: make(map[string]types.QueryTypeValidationFunction),
+	}
+
+	qv.AddParamPattern("default", `^[a-zA-Z][a-zA-Z0-9_]*$`)
+
+	qv.typeValidators["number"] = func(v string) bool {
+		matched, _ := regexp.MatchString(`^-?\d+(\.\d+)?$`, v)
+		return matched
+	}
+
+	qv.typeValidators["boolean"] = func(v string) bool {
+		v = strings.ToLower(v)
+		return v == "true" || v == "false" || v == "1" || v == "0"
+	}
+
+	qv.typeValidators["date"] = func(v string) bool {
+		matched, _ := regexp.MatchString(`^\d{4}-\d{2}-\d{2}$`, v)
+		return matched
+	}
+
+	return qv
 }
 
 func (qv *QueryValidator) AddParamPattern(name, pattern string) error {
-    regex, err := regexp.Compile(pattern)
-    if err != nil {
-        return fmt.Errorf("invalid pattern for %s: %v", name, err)
-    }
-    qv.paramPatterns[name] = regex
-    return nil
+	regex, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid pattern for %s: %v", name, err)
+	}
+	qv.paramPatterns[name] = regex
+	return nil
 }
 
 func (qv *QueryValidator) AddTypeValidator(name string, validator types.QueryTypeValidationFunction) {
-    qv.typeValidators[name] = validator
+	qv.typeValidators[name] = validator
 }
 
 func (qv *QueryValidator) ValidateQuery(c fiber.Ctx, rules map[string]string) []QueryValidationError {
-    var errors []QueryValidationError
-    
-    queries := c.Queries()
-    
-    for param, value := range queries {
-        if !qv.validateParamName(param) {
-            errors = append(errors, QueryValidationError{
-                Parameter: param,
-                Value:     value,
-                Message:   "invalid parameter name format",
-            })
-            continue
-        }
-        
-        expectedType, exists := rules[param]
-        if !exists {
-            errors = append(errors, QueryValidationError{
-                Parameter: param,
-                Value:     value,
-                Message:   "unexpected parameter",
-            })
-            continue
-        }
-        
-        if !qv.validateParamValue(value, expectedType) {
-            errors = append(errors, QueryValidationError{
-                Parameter: param,
-                Value:     value,
-                Message:   fmt.Sprintf("invalid value for type %s", expectedType),
-            })
-        }
-    }
-    
-    return errors
+	var errors []QueryValidationError
+
+	queries := c.Queries()
+
+	for param, value := range queries {
+		if !qv.validateParamName(param) {
+			errors = append(errors, QueryValidationError{
+				Parameter: param,
+				Value:     value,
+				Code:      QueryCodeInvalidName,
+				Message:   "invalid parameter name format",
+			})
+			continue
+		}
+
+		expectedType, exists := rules[param]
+		if !exists {
+			errors = append(errors, QueryValidationError{
+				Parameter: param,
+				Value:     value,
+				Code:      QueryCodeUnexpected,
+				Message:   "unexpected parameter",
+			})
+			continue
+		}
+
+		if !qv.validateParamValue(value, expectedType) {
+			errors = append(errors, QueryValidationError{
+				Parameter: param,
+				Value:     value,
+				Code:      QueryCodeInvalidType,
+				Message:   fmt.Sprintf("invalid value for type %s", expectedType),
+				Params:    []string{expectedType},
+			})
+		}
+	}
+
+	return errors
 }
 
 func (qv *QueryValidator) validateParamName(param string) bool {
-    pattern, exists := qv.paramPatterns["default"]
-    if !exists {
-        return true 
-    }
-    return pattern.MatchString(param)
+	pattern, exists := qv.paramPatterns["default"]
+	if !exists {
+		return true
+	}
+	return pattern.MatchString(param)
 }
 
 func (qv *QueryValidator) validateParamValue(value, expectedType string) bool {
-    validator, exists := qv.typeValidators[expectedType]
-    if !exists {
-        return true 
-    }
-    return validator(value)
+	validator, exists := qv.typeValidators[expectedType]
+	if !exists {
+		return true
+	}
+	return validator(value)
 }
-